@@ -3,44 +3,73 @@ package alien
 import (
 	"math/rand"
 
-	rl "github.com/gen2brain/raylib-go/raylib"
 	"github.com/santihernandezc/alien-invasion/world"
 )
 
 type Alien struct {
-	ID           int
-	City         *world.City
-	Position     rl.Vector2
-	NextPosition rl.Vector2
-	Texture      rl.Texture2D
-	isDeleted    bool
+	ID int
+	// Position is the city the alien is currently in, or the one it left if it's traveling
+	Position *world.City
+	// Destination is the city the alien is traveling to, nil unless it's on a road
+	Destination *world.City
+	// Strategy decides where the alien goes next, aliens walk randomly if it's nil
+	Strategy  Strategy
+	isDeleted bool
+	// isolated tells whether the alien can't meet any other alien anymore
+	isolated bool
+	// travel is the amount of turns the current trip takes, and traveled the ones spent on it so far
+	travel   int
+	traveled int
 }
 
-func (a *Alien) move() (ok bool) {
+// Traveling reports whether the alien is on a road, between Position and Destination.
+func (a *Alien) Traveling() bool {
+	return a.Destination != nil
+}
+
+// Progress returns how much of the road the alien traveled, between 0 and 1, or 0 if it's not traveling.
+func (a *Alien) Progress() float32 {
+	if !a.Traveling() || a.travel == 0 {
+		return 0
+	}
+	return float32(a.traveled) / float32(a.travel)
+}
+
+func (a *Alien) move(v View, rng *rand.Rand) (ok bool) {
 	// Check whether the alien is trapped
-	neighbors := a.City.Neighbors
+	neighbors := a.Position.Neighbors
 	if len(neighbors) < 1 {
 		return false
 	}
 
-	// Move to a random city
-	a.City = neighbors[rand.Intn(len(neighbors))]
-	a.NextPosition = rl.NewVector2(float32(a.City.Position.X), float32(a.City.Position.Y))
+	// Move to the city chosen by the strategy
+	strategy := a.Strategy
+	if strategy == nil {
+		strategy = RandomWalk{}
+	}
+	next := strategy.Next(a, v, rng)
+
+	// Roads taking more than one turn leave the alien on the road
+	if v != nil {
+		if turns := v.TravelTime(a.Position, next); turns > 1 {
+			a.Destination = next
+			a.travel, a.traveled = turns, 0
+			return true
+		}
+	}
+	a.Position = next
 
 	return true
 }
 
-func (a *Alien) Draw() {
-	// Modify position if needed
-	distance := rl.Vector2Distance(a.Position, a.NextPosition)
-	if distance != 0 {
-		if distance < 0.1 {
-			a.Position = a.NextPosition
-		} else {
-			a.Position = rl.Vector2Add(a.Position, rl.Vector2Scale(rl.Vector2Subtract(a.NextPosition, a.Position), 0.1))
-		}
+// advance moves a traveling alien one turn further on its road, and reports whether it arrived.
+func (a *Alien) advance() (arrived bool) {
+	a.traveled++
+	if a.traveled < a.travel {
+		return false
 	}
 
-	// Draw
-	rl.DrawTexture(a.Texture, int32(a.Position.X)-a.Texture.Width/2, int32(a.Position.Y)-a.Texture.Height/2, rl.White)
+	a.Position, a.Destination = a.Destination, nil
+	a.travel, a.traveled = 0, 0
+	return true
 }
@@ -1,6 +1,7 @@
 package alien
 
 import (
+	"math/rand"
 	"testing"
 
 	"github.com/santihernandezc/alien-invasion/world"
@@ -74,7 +75,7 @@ func TestMove(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(tt *testing.T) {
-			ok := test.alien.move()
+			ok := test.alien.move(nil, rand.New(rand.NewSource(0)))
 			if test.trapped {
 				assert.False(tt, ok, "Alien should be trapped")
 				return
@@ -0,0 +1,188 @@
+package alien
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Event types used in the JSON Lines event log.
+const (
+	eventTypeAlienDeparted   = "alien_departed"
+	eventTypeAlienMoved      = "alien_moved"
+	eventTypeAliensMet       = "aliens_met"
+	eventTypeAliensCrossed   = "aliens_crossed"
+	eventTypeAliensKilled    = "aliens_killed"
+	eventTypeCityDamaged     = "city_damaged"
+	eventTypeCityDestroyed   = "city_destroyed"
+	eventTypeRoadDestroyed   = "road_destroyed"
+	eventTypeRoadBlocked     = "road_blocked"
+	eventTypeRoadReopened    = "road_reopened"
+	eventTypeAlienTrapped    = "alien_trapped"
+	eventTypeAlienIsolated   = "alien_isolated"
+	eventTypeSimulationEnded = "simulation_ended"
+)
+
+// eventRecord is the representation of an event in the JSON Lines event log.
+// Seq, Turn and Type are always present, the rest of the fields depend on the event type.
+type eventRecord struct {
+	Seq  int    `json:"seq"`
+	Turn int    `json:"turn"`
+	Type string `json:"type"`
+	// Alien is the alien that departed, moved, met other aliens, got trapped or isolated
+	Alien int `json:"alien,omitempty"`
+	// Aliens are the rivals found by Alien, the aliens killed in City or the ones that destroyed it,
+	// or the aliens going From -> To when crossing each other
+	Aliens []int `json:"aliens,omitempty"`
+	// Rivals are the aliens going To -> From when crossing each other
+	Rivals     []int  `json:"rivals,omitempty"`
+	From       string `json:"from,omitempty"`
+	To         string `json:"to,omitempty"`
+	City       string `json:"city,omitempty"`
+	Turns      int    `json:"turns,omitempty"`
+	InRuins    *bool  `json:"in_ruins,omitempty"`
+	OnRoad     bool   `json:"on_road,omitempty"`
+	AliensLeft *int   `json:"aliens_left,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+	// HitPoints are the hit points a damaged city has left
+	HitPoints      int  `json:"hit_points,omitempty"`
+	PopulationLost int  `json:"population_lost,omitempty"`
+	Repelled       bool `json:"repelled,omitempty"`
+	Retired        bool `json:"retired,omitempty"`
+}
+
+// JSONLSubscriber is a Subscriber that writes each event as a JSON object in its own line,
+// numbered with a sequence starting at 1.
+type JSONLSubscriber struct {
+	enc *json.Encoder
+	seq int
+	err error
+}
+
+// NewJSONLSubscriber returns a JSONLSubscriber that writes to w.
+func NewJSONLSubscriber(w io.Writer) *JSONLSubscriber {
+	return &JSONLSubscriber{enc: json.NewEncoder(w)}
+}
+
+// Notify writes an event, unless a previous one failed to be written.
+func (s *JSONLSubscriber) Notify(e Event) {
+	if s.err != nil {
+		return
+	}
+
+	s.seq++
+	record := newEventRecord(e)
+	record.Seq = s.seq
+
+	// Subscribers can't fail, so the error is kept for Err
+	if err := s.enc.Encode(record); err != nil {
+		s.err = fmt.Errorf("error writing event %d: %w", s.seq, err)
+	}
+}
+
+// Err returns the first error writing an event, after which the rest are dropped.
+func (s *JSONLSubscriber) Err() error {
+	return s.err
+}
+
+func newEventRecord(e Event) eventRecord {
+	switch e := e.(type) {
+	case AlienDeparted:
+		return eventRecord{Turn: e.Turn, Type: eventTypeAlienDeparted, Alien: e.AlienID, From: e.From, To: e.To, Turns: e.Turns}
+	case AlienMoved:
+		return eventRecord{Turn: e.Turn, Type: eventTypeAlienMoved, Alien: e.AlienID, From: e.From, To: e.To}
+	case AliensMet:
+		return eventRecord{Turn: e.Turn, Type: eventTypeAliensMet, Alien: e.AlienID, Aliens: e.RivalIDs, City: e.City}
+	case AliensCrossed:
+		return eventRecord{Turn: e.Turn, Type: eventTypeAliensCrossed, Aliens: e.AlienIDs, Rivals: e.RivalIDs, From: e.From, To: e.To}
+	case AliensKilled:
+		return eventRecord{Turn: e.Turn, Type: eventTypeAliensKilled, Aliens: e.AlienIDs, City: e.City, OnRoad: e.OnRoad}
+	case CityDamaged:
+		return eventRecord{Turn: e.Turn, Type: eventTypeCityDamaged, Aliens: e.AlienIDs, City: e.City, HitPoints: e.HitPoints, PopulationLost: e.PopulationLost, Repelled: e.Repelled}
+	case CityDestroyed:
+		return eventRecord{Turn: e.Turn, Type: eventTypeCityDestroyed, Aliens: e.AlienIDs, City: e.City}
+	case RoadDestroyed:
+		return eventRecord{Turn: e.Turn, Type: eventTypeRoadDestroyed, From: e.From, To: e.To}
+	case RoadBlocked:
+		return eventRecord{Turn: e.Turn, Type: eventTypeRoadBlocked, From: e.From, To: e.To, Turns: e.Turns}
+	case RoadReopened:
+		return eventRecord{Turn: e.Turn, Type: eventTypeRoadReopened, From: e.From, To: e.To}
+	case AlienTrapped:
+		inRuins := e.InRuins
+		return eventRecord{Turn: e.Turn, Type: eventTypeAlienTrapped, Alien: e.AlienID, City: e.City, InRuins: &inRuins}
+	case AlienIsolated:
+		return eventRecord{Turn: e.Turn, Type: eventTypeAlienIsolated, Alien: e.AlienID, City: e.City, Retired: e.Retired}
+	case SimulationEnded:
+		aliensLeft := e.AliensLeft
+		return eventRecord{Turn: e.Turn, Type: eventTypeSimulationEnded, AliensLeft: &aliensLeft, Reason: string(e.Reason)}
+	}
+
+	return eventRecord{}
+}
+
+// ReadEvents reads a JSON Lines event log, as written by NewJSONLSubscriber.
+func ReadEvents(r io.Reader) ([]Event, error) {
+	var events []Event
+
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var record eventRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("error unmarshaling line %d: %w", lineNumber, err)
+		}
+
+		e, err := record.event()
+		if err != nil {
+			return nil, fmt.Errorf("error reading line %d: %w", lineNumber, err)
+		}
+		events = append(events, e)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading event log: %w", err)
+	}
+
+	return events, nil
+}
+
+func (r eventRecord) event() (Event, error) {
+	switch r.Type {
+	case eventTypeAlienDeparted:
+		return AlienDeparted{Turn: r.Turn, AlienID: r.Alien, From: r.From, To: r.To, Turns: r.Turns}, nil
+	case eventTypeAlienMoved:
+		return AlienMoved{Turn: r.Turn, AlienID: r.Alien, From: r.From, To: r.To}, nil
+	case eventTypeAliensMet:
+		return AliensMet{Turn: r.Turn, AlienID: r.Alien, RivalIDs: r.Aliens, City: r.City}, nil
+	case eventTypeAliensCrossed:
+		return AliensCrossed{Turn: r.Turn, AlienIDs: r.Aliens, RivalIDs: r.Rivals, From: r.From, To: r.To}, nil
+	case eventTypeAliensKilled:
+		return AliensKilled{Turn: r.Turn, City: r.City, AlienIDs: r.Aliens, OnRoad: r.OnRoad}, nil
+	case eventTypeCityDamaged:
+		return CityDamaged{Turn: r.Turn, City: r.City, AlienIDs: r.Aliens, HitPoints: r.HitPoints, PopulationLost: r.PopulationLost, Repelled: r.Repelled}, nil
+	case eventTypeCityDestroyed:
+		return CityDestroyed{Turn: r.Turn, City: r.City, AlienIDs: r.Aliens}, nil
+	case eventTypeRoadDestroyed:
+		return RoadDestroyed{Turn: r.Turn, From: r.From, To: r.To}, nil
+	case eventTypeRoadBlocked:
+		return RoadBlocked{Turn: r.Turn, From: r.From, To: r.To, Turns: r.Turns}, nil
+	case eventTypeRoadReopened:
+		return RoadReopened{Turn: r.Turn, From: r.From, To: r.To}, nil
+	case eventTypeAlienTrapped:
+		return AlienTrapped{Turn: r.Turn, AlienID: r.Alien, City: r.City, InRuins: r.InRuins != nil && *r.InRuins}, nil
+	case eventTypeAlienIsolated:
+		return AlienIsolated{Turn: r.Turn, AlienID: r.Alien, City: r.City, Retired: r.Retired}, nil
+	case eventTypeSimulationEnded:
+		var aliensLeft int
+		if r.AliensLeft != nil {
+			aliensLeft = *r.AliensLeft
+		}
+		return SimulationEnded{Turn: r.Turn, AliensLeft: aliensLeft, Reason: StopReason(r.Reason)}, nil
+	}
+
+	return nil, fmt.Errorf("invalid event type: %q", r.Type)
+}
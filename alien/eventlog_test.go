@@ -0,0 +1,124 @@
+package alien
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONLSubscriber(t *testing.T) {
+	events := []Event{
+		AlienMoved{Turn: 1, AlienID: 2, From: "Avellaneda", To: "Lanús"},
+		AliensMet{Turn: 1, AlienID: 2, RivalIDs: []int{3}, City: "Lanús"},
+		AliensKilled{Turn: 1, City: "Lanús", AlienIDs: []int{2, 3}},
+		CityDestroyed{Turn: 1, City: "Lanús", AlienIDs: []int{2, 3}},
+		AlienTrapped{Turn: 2, AlienID: 1, City: "Gerli"},
+		AliensCrossed{Turn: 2, AlienIDs: []int{4}, RivalIDs: []int{5}, From: "Gerli", To: "Bernal"},
+		AliensKilled{Turn: 2, City: "Bernal", AlienIDs: []int{4}, OnRoad: true},
+		AlienDeparted{Turn: 2, AlienID: 5, From: "Bernal", To: "Quilmes", Turns: 3},
+		CityDamaged{Turn: 2, City: "Gerli", AlienIDs: []int{6, 7}, HitPoints: 1, PopulationLost: 250},
+		CityDamaged{Turn: 2, City: "Quilmes", AlienIDs: []int{8, 9}, HitPoints: 2, Repelled: true},
+		RoadDestroyed{Turn: 2, From: "Gerli", To: "Bernal"},
+		RoadBlocked{Turn: 2, From: "Quilmes", To: "Bernal", Turns: 3},
+		RoadReopened{Turn: 5, From: "Quilmes", To: "Bernal"},
+		AlienIsolated{Turn: 5, AlienID: 10, City: "Bernal"},
+		AlienIsolated{Turn: 5, AlienID: 11, City: "Quilmes", Retired: true},
+		SimulationEnded{Turn: 2, AliensLeft: 0, Reason: StopNoAliens},
+	}
+
+	var buf bytes.Buffer
+	s := NewJSONLSubscriber(&buf)
+	for _, e := range events {
+		s.Notify(e)
+	}
+
+	expected := `{"seq":1,"turn":1,"type":"alien_moved","alien":2,"from":"Avellaneda","to":"Lanús"}
+{"seq":2,"turn":1,"type":"aliens_met","alien":2,"aliens":[3],"city":"Lanús"}
+{"seq":3,"turn":1,"type":"aliens_killed","aliens":[2,3],"city":"Lanús"}
+{"seq":4,"turn":1,"type":"city_destroyed","aliens":[2,3],"city":"Lanús"}
+{"seq":5,"turn":2,"type":"alien_trapped","alien":1,"city":"Gerli","in_ruins":false}
+{"seq":6,"turn":2,"type":"aliens_crossed","aliens":[4],"rivals":[5],"from":"Gerli","to":"Bernal"}
+{"seq":7,"turn":2,"type":"aliens_killed","aliens":[4],"city":"Bernal","on_road":true}
+{"seq":8,"turn":2,"type":"alien_departed","alien":5,"from":"Bernal","to":"Quilmes","turns":3}
+{"seq":9,"turn":2,"type":"city_damaged","aliens":[6,7],"city":"Gerli","hit_points":1,"population_lost":250}
+{"seq":10,"turn":2,"type":"city_damaged","aliens":[8,9],"city":"Quilmes","hit_points":2,"repelled":true}
+{"seq":11,"turn":2,"type":"road_destroyed","from":"Gerli","to":"Bernal"}
+{"seq":12,"turn":2,"type":"road_blocked","from":"Quilmes","to":"Bernal","turns":3}
+{"seq":13,"turn":5,"type":"road_reopened","from":"Quilmes","to":"Bernal"}
+{"seq":14,"turn":5,"type":"alien_isolated","alien":10,"city":"Bernal"}
+{"seq":15,"turn":5,"type":"alien_isolated","alien":11,"city":"Quilmes","retired":true}
+{"seq":16,"turn":2,"type":"simulation_ended","aliens_left":0,"reason":"no_aliens"}
+`
+	assert.Equal(t, expected, buf.String())
+	assert.NoError(t, s.Err())
+
+	// Events can be read back
+	read, err := ReadEvents(&buf)
+	if assert.NoError(t, err) {
+		assert.Equal(t, events, read)
+	}
+}
+
+// limitedWriter fails after writing a number of times.
+type limitedWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if w.writes == 0 {
+		return 0, errors.New("disk full")
+	}
+	w.writes--
+	return w.Buffer.Write(p)
+}
+
+func TestJSONLSubscriberError(t *testing.T) {
+	w := &limitedWriter{writes: 1}
+	s := NewJSONLSubscriber(w)
+	s.Notify(AlienMoved{Turn: 1, AlienID: 1, From: "Gerli", To: "Lanús"})
+	s.Notify(AlienMoved{Turn: 1, AlienID: 2, From: "Bernal", To: "Quilmes"})
+	s.Notify(AlienMoved{Turn: 2, AlienID: 1, From: "Lanús", To: "Gerli"})
+
+	// The first error is kept, and no events are written after it
+	assert.EqualError(t, s.Err(), "error writing event 2: disk full")
+	assert.Equal(t, `{"seq":1,"turn":1,"type":"alien_moved","alien":1,"from":"Gerli","to":"Lanús"}`+"\n", w.String())
+}
+
+func TestReadEvents(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{
+			"empty log",
+			"",
+			"",
+		},
+		{
+			"invalid JSON",
+			`{"seq":1,"turn":1,"type":"alien_moved"}` + "\n{",
+			"error unmarshaling line 2: unexpected end of JSON input",
+		},
+		{
+			"invalid event type",
+			`{"seq":1,"turn":1,"type":"alien_exploded"}`,
+			`error reading line 1: invalid event type: "alien_exploded"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			_, err := ReadEvents(strings.NewReader(test.input))
+			if test.err != "" {
+				assert.EqualError(tt, err, test.err)
+				return
+			}
+			assert.NoError(tt, err)
+		})
+	}
+}
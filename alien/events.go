@@ -0,0 +1,255 @@
+package alien
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Event is something that happened during the simulation.
+// It's one of AlienDeparted, AlienMoved, AliensMet, AliensCrossed, AliensKilled, CityDamaged, CityDestroyed,
+// RoadDestroyed, RoadBlocked, RoadReopened, AlienTrapped, AlienIsolated or SimulationEnded.
+type Event interface {
+	fmt.Stringer
+	isEvent()
+}
+
+// AlienDeparted is emitted when an alien takes a road that takes more than one turn to travel.
+// AlienMoved is emitted once it arrives.
+type AlienDeparted struct {
+	Turn    int
+	AlienID int
+	From    string
+	To      string
+	// Turns is the amount of turns the trip takes, including the current one
+	Turns int
+}
+
+// AlienMoved is emitted when an alien moves from one city to another.
+type AlienMoved struct {
+	Turn    int
+	AlienID int
+	From    string
+	To      string
+}
+
+// AliensMet is emitted when an alien arrives to a city where there are other aliens.
+type AliensMet struct {
+	Turn    int
+	AlienID int
+	// RivalIDs are the aliens that were already in the city
+	RivalIDs []int
+	City     string
+}
+
+// AliensCrossed is emitted when aliens traveling the same road in opposite directions meet.
+type AliensCrossed struct {
+	Turn int
+	// AlienIDs are the aliens going from From to To
+	AlienIDs []int
+	// RivalIDs are the aliens going from To to From
+	RivalIDs []int
+	From     string
+	To       string
+}
+
+// AliensKilled is emitted when aliens die in a fight.
+type AliensKilled struct {
+	Turn     int
+	City     string
+	AlienIDs []int
+	// OnRoad tells whether the aliens were killed on the road to City, instead of in it
+	OnRoad bool
+}
+
+// CityDamaged is emitted when a city withstands a fight, either because it repelled the fight
+// or because it had hit points left after it.
+type CityDamaged struct {
+	Turn     int
+	City     string
+	AlienIDs []int
+	// HitPoints are the hit points the city has left
+	HitPoints      int
+	PopulationLost int
+	Repelled       bool
+}
+
+// CityDestroyed is emitted when a city is destroyed in a fight between aliens.
+type CityDestroyed struct {
+	Turn int
+	City string
+	// AlienIDs are the aliens that fought and destroyed the city
+	AlienIDs []int
+}
+
+// RoadDestroyed is emitted when a road is destroyed for good. Unless the World is directed,
+// the road is destroyed both ways.
+type RoadDestroyed struct {
+	Turn int
+	From string
+	To   string
+}
+
+// RoadBlocked is emitted when a road is blocked for some turns. Unless the World is directed,
+// the road is blocked both ways.
+type RoadBlocked struct {
+	Turn  int
+	From  string
+	To    string
+	Turns int
+}
+
+// RoadReopened is emitted when a blocked road opens again.
+type RoadReopened struct {
+	Turn int
+	From string
+	To   string
+}
+
+// AlienTrapped is emitted when an alien can't move anymore.
+type AlienTrapped struct {
+	Turn    int
+	AlienID int
+	City    string
+	// InRuins tells whether the alien is trapped because the city it's in was destroyed
+	InRuins bool
+}
+
+// AlienIsolated is emitted when no other alien can get to any of the cities an alien can get to,
+// so they can't meet anymore.
+type AlienIsolated struct {
+	Turn    int
+	AlienID int
+	// City is where the alien is, or the city it's going to if it's on a road
+	City string
+	// Retired tells whether the alien was taken out of the simulation
+	Retired bool
+}
+
+// SimulationEnded is emitted when the simulation is over.
+type SimulationEnded struct {
+	Turn       int
+	AliensLeft int
+	Reason     StopReason
+}
+
+func (AlienDeparted) isEvent()   {}
+func (AlienMoved) isEvent()      {}
+func (AliensMet) isEvent()       {}
+func (AliensCrossed) isEvent()   {}
+func (AliensKilled) isEvent()    {}
+func (CityDamaged) isEvent()     {}
+func (CityDestroyed) isEvent()   {}
+func (RoadDestroyed) isEvent()   {}
+func (RoadBlocked) isEvent()     {}
+func (RoadReopened) isEvent()    {}
+func (AlienTrapped) isEvent()    {}
+func (AlienIsolated) isEvent()   {}
+func (SimulationEnded) isEvent() {}
+
+func (e AlienDeparted) String() string {
+	return fmt.Sprintf("🛸 Alien %d left %s for %s, %d turns away", e.AlienID, e.From, e.To, e.Turns)
+}
+
+func (e AlienMoved) String() string {
+	return fmt.Sprintf("👾 Alien %d moved from %s to %s", e.AlienID, e.From, e.To)
+}
+
+func (e AliensMet) String() string {
+	return fmt.Sprintf("👀 Alien %d found %s in %s", e.AlienID, joinAliens(e.RivalIDs), e.City)
+}
+
+func (e AliensCrossed) String() string {
+	return fmt.Sprintf("⚔️  %s crossed %s on the road between %s and %s", joinAliens(e.AlienIDs), joinAliens(e.RivalIDs), e.From, e.To)
+}
+
+func (e AliensKilled) String() string {
+	verb := "was"
+	if len(e.AlienIDs) > 1 {
+		verb = "were"
+	}
+	if e.OnRoad {
+		return fmt.Sprintf("☠️  %s %s killed on the road to %s", joinAliens(e.AlienIDs), verb, e.City)
+	}
+	return fmt.Sprintf("☠️  %s %s killed in %s", joinAliens(e.AlienIDs), verb, e.City)
+}
+
+func (e CityDamaged) String() string {
+	if e.Repelled {
+		return fmt.Sprintf("🛡️  %s repelled the attack of %s", e.City, joinAliens(e.AlienIDs))
+	}
+	if e.PopulationLost > 0 {
+		return fmt.Sprintf("🔥 %s was damaged by %s and lost %d people, %d hit points left", e.City, joinAliens(e.AlienIDs), e.PopulationLost, e.HitPoints)
+	}
+	return fmt.Sprintf("🔥 %s was damaged by %s, %d hit points left", e.City, joinAliens(e.AlienIDs), e.HitPoints)
+}
+
+func (e CityDestroyed) String() string {
+	return fmt.Sprintf("💥 %s has been destroyed by %s", e.City, joinAliens(e.AlienIDs))
+}
+
+func (e RoadDestroyed) String() string {
+	return fmt.Sprintf("🚧 The road from %s to %s has been destroyed", e.From, e.To)
+}
+
+func (e RoadBlocked) String() string {
+	return fmt.Sprintf("🚧 The road from %s to %s is blocked for %d turns", e.From, e.To, e.Turns)
+}
+
+func (e RoadReopened) String() string {
+	return fmt.Sprintf("🛣️  The road from %s to %s is open again", e.From, e.To)
+}
+
+func (e AlienTrapped) String() string {
+	if e.InRuins {
+		return fmt.Sprintf("🚷 Alien %d is trapped forever in the ruins of %s", e.AlienID, e.City)
+	}
+	return fmt.Sprintf("🚷 Alien %d is trapped forever in %s", e.AlienID, e.City)
+}
+
+func (e AlienIsolated) String() string {
+	if e.Retired {
+		return fmt.Sprintf("🏝️  Alien %d can't meet any other alien from %s and retires", e.AlienID, e.City)
+	}
+	return fmt.Sprintf("🏝️  Alien %d can't meet any other alien from %s", e.AlienID, e.City)
+}
+
+func (e SimulationEnded) String() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("🏁 Simulation ended after %d turns with %d aliens left, %s", e.Turn, e.AliensLeft, e.Reason)
+	}
+	return fmt.Sprintf("🏁 Simulation ended after %d turns with %d aliens left", e.Turn, e.AliensLeft)
+}
+
+// joinAliens returns a list of aliens in the format "Alien 1, Alien 2 and Alien 3".
+func joinAliens(ids []int) string {
+	names := make([]string, len(ids))
+	for i, id := range ids {
+		names[i] = fmt.Sprintf("Alien %d", id)
+	}
+
+	if len(names) < 2 {
+		return strings.Join(names, "")
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
+
+// Subscriber receives the events of a simulation as they happen.
+type Subscriber interface {
+	Notify(e Event)
+}
+
+// SubscriberFunc allows using a function as a Subscriber.
+type SubscriberFunc func(e Event)
+
+// Notify calls f(e).
+func (f SubscriberFunc) Notify(e Event) {
+	f(e)
+}
+
+// NewLogSubscriber returns a Subscriber that logs every event in a human-readable format.
+func NewLogSubscriber(log *log.Logger) Subscriber {
+	return SubscriberFunc(func(e Event) {
+		log.Print(e)
+	})
+}
@@ -0,0 +1,181 @@
+package alien
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/santihernandezc/alien-invasion/world"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventString(t *testing.T) {
+	tests := []struct {
+		name     string
+		event    Event
+		expected string
+	}{
+		{
+			"alien departed",
+			AlienDeparted{Turn: 1, AlienID: 2, From: "Avellaneda", To: "Lanús", Turns: 3},
+			"🛸 Alien 2 left Avellaneda for Lanús, 3 turns away",
+		},
+		{
+			"alien moved",
+			AlienMoved{Turn: 1, AlienID: 2, From: "Avellaneda", To: "Lanús"},
+			"👾 Alien 2 moved from Avellaneda to Lanús",
+		},
+		{
+			"aliens met",
+			AliensMet{Turn: 1, AlienID: 2, RivalIDs: []int{3}, City: "Escalada"},
+			"👀 Alien 2 found Alien 3 in Escalada",
+		},
+		{
+			"aliens met, several rivals",
+			AliensMet{Turn: 1, AlienID: 2, RivalIDs: []int{3, 4, 5}, City: "Escalada"},
+			"👀 Alien 2 found Alien 3, Alien 4 and Alien 5 in Escalada",
+		},
+		{
+			"aliens crossed",
+			AliensCrossed{Turn: 1, AlienIDs: []int{2}, RivalIDs: []int{3, 4}, From: "Gerli", To: "Lanús"},
+			"⚔️  Alien 2 crossed Alien 3 and Alien 4 on the road between Gerli and Lanús",
+		},
+		{
+			"alien killed on a road",
+			AliensKilled{Turn: 1, City: "Lanús", AlienIDs: []int{2}, OnRoad: true},
+			"☠️  Alien 2 was killed on the road to Lanús",
+		},
+		{
+			"alien killed",
+			AliensKilled{Turn: 1, City: "Escalada", AlienIDs: []int{2}},
+			"☠️  Alien 2 was killed in Escalada",
+		},
+		{
+			"aliens killed",
+			AliensKilled{Turn: 1, City: "Escalada", AlienIDs: []int{2, 3}},
+			"☠️  Alien 2 and Alien 3 were killed in Escalada",
+		},
+		{
+			"city damaged",
+			CityDamaged{Turn: 1, City: "Escalada", AlienIDs: []int{2, 3}, HitPoints: 2},
+			"🔥 Escalada was damaged by Alien 2 and Alien 3, 2 hit points left",
+		},
+		{
+			"city damaged, population lost",
+			CityDamaged{Turn: 1, City: "Escalada", AlienIDs: []int{2, 3}, HitPoints: 1, PopulationLost: 500},
+			"🔥 Escalada was damaged by Alien 2 and Alien 3 and lost 500 people, 1 hit points left",
+		},
+		{
+			"city repelled the attack",
+			CityDamaged{Turn: 1, City: "Escalada", AlienIDs: []int{2, 3}, HitPoints: 2, Repelled: true},
+			"🛡️  Escalada repelled the attack of Alien 2 and Alien 3",
+		},
+		{
+			"city destroyed",
+			CityDestroyed{Turn: 1, City: "Escalada", AlienIDs: []int{2, 3}},
+			"💥 Escalada has been destroyed by Alien 2 and Alien 3",
+		},
+		{
+			"road destroyed",
+			RoadDestroyed{Turn: 1, From: "Gerli", To: "Bernal"},
+			"🚧 The road from Gerli to Bernal has been destroyed",
+		},
+		{
+			"road blocked",
+			RoadBlocked{Turn: 1, From: "Gerli", To: "Bernal", Turns: 3},
+			"🚧 The road from Gerli to Bernal is blocked for 3 turns",
+		},
+		{
+			"road reopened",
+			RoadReopened{Turn: 4, From: "Gerli", To: "Bernal"},
+			"🛣️  The road from Gerli to Bernal is open again",
+		},
+		{
+			"alien trapped",
+			AlienTrapped{Turn: 1, AlienID: 1, City: "Gerli"},
+			"🚷 Alien 1 is trapped forever in Gerli",
+		},
+		{
+			"alien isolated",
+			AlienIsolated{Turn: 1, AlienID: 1, City: "Gerli"},
+			"🏝️  Alien 1 can't meet any other alien from Gerli",
+		},
+		{
+			"alien isolated and retired",
+			AlienIsolated{Turn: 1, AlienID: 1, City: "Gerli", Retired: true},
+			"🏝️  Alien 1 can't meet any other alien from Gerli and retires",
+		},
+		{
+			"simulation ended",
+			SimulationEnded{Turn: 10, AliensLeft: 1},
+			"🏁 Simulation ended after 10 turns with 1 aliens left",
+		},
+		{
+			"simulation ended with a reason",
+			SimulationEnded{Turn: 10, AliensLeft: 2, Reason: StopAliensIsolated},
+			"🏁 Simulation ended after 10 turns with 2 aliens left, no alien can meet another one",
+		},
+		{
+			"alien trapped in ruins",
+			AlienTrapped{Turn: 1, AlienID: 1, City: "Gerli", InRuins: true},
+			"🚷 Alien 1 is trapped forever in the ruins of Gerli",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			assert.Equal(tt, test.expected, test.event.String())
+		})
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	worldDef := "City1 south=City2\nCity2 north=City1"
+	w, err := world.NewFromReader(strings.NewReader(worldDef), false)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// With this seed the aliens start in different cities
+	ao, err := NewOrchestrator(2, 3, w, DefaultRules(), nopLogger)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var events []Event
+	ao.Subscribe(SubscriberFunc(func(e Event) {
+		events = append(events, e)
+	}))
+
+	ao.Run(StopConditions{MaxTurns: 1})
+
+	// The first alien moves to the city of the second one, they kill each other and destroy it
+	if !assert.Equal(t, 5, len(events)) {
+		return
+	}
+
+	moved, ok := events[0].(AlienMoved)
+	if assert.True(t, ok) {
+		assert.Equal(t, 1, moved.Turn)
+	}
+
+	met, ok := events[1].(AliensMet)
+	if assert.True(t, ok) {
+		assert.Equal(t, moved.To, met.City)
+		assert.Equal(t, moved.AlienID, met.AlienID)
+		assert.Equal(t, 1, len(met.RivalIDs))
+	}
+
+	killed, ok := events[2].(AliensKilled)
+	if assert.True(t, ok) {
+		assert.Equal(t, moved.To, killed.City)
+		assert.ElementsMatch(t, []int{1, 2}, killed.AlienIDs)
+	}
+
+	destroyed, ok := events[3].(CityDestroyed)
+	if assert.True(t, ok) {
+		assert.Equal(t, moved.To, destroyed.City)
+		assert.ElementsMatch(t, []int{1, 2}, destroyed.AlienIDs)
+	}
+
+	assert.Equal(t, SimulationEnded{Turn: 1, AliensLeft: 0, Reason: StopNoAliens}, events[4])
+}
@@ -0,0 +1,265 @@
+package alien
+
+import "github.com/santihernandezc/alien-invasion/world"
+
+// step is the part of the state of the simulation changed by a step, recorded to undo or
+// redo it. It has the values from before the step while it can be undone, and the values
+// from after the step while it can be redone.
+type step struct {
+	turn   int
+	cursor int
+	inTurn bool
+	aliens []*Alien
+	// draws is the amount of values drawn from the random source
+	draws uint64
+	// states has the state of every alien changed by the step
+	states map[*Alien]alienState
+	// positions has the aliens of every city changed by the step
+	positions map[string][]*Alien
+	// damage has the hit points lost by every city damaged by the step
+	damage map[string]int
+	// changes are the changes made to the World by the step, which undo it while the
+	// step is done and redo it while it's undone
+	changes []world.Change
+}
+
+// alienState is the state of an alien that changes when it moves or dies.
+type alienState struct {
+	position    *world.City
+	destination *world.City
+	travel      int
+	traveled    int
+	deleted     bool
+	isolated    bool
+	// recent are the cities remembered by an AvoidRecent strategy
+	recent []*world.City
+}
+
+// KeepHistory makes the orchestrator remember the last steps, up to the given amount,
+// so that they can be undone with StepBack. History isn't kept by default.
+func (ao *AlienOrchestrator) KeepHistory(steps int) {
+	ao.historySize = steps
+	if len(ao.history) > steps {
+		ao.history = append([]*step(nil), ao.history[len(ao.history)-steps:]...)
+	}
+}
+
+// StepBack undoes the last step and reports whether there was a step in the history to undo.
+// Undone steps are redone by StepAlien and StepRound before moving the aliens again, so the
+// simulation goes on exactly as it would have. No events are emitted when steps are undone or redone.
+func (ao *AlienOrchestrator) StepBack() bool {
+	if len(ao.history) == 0 {
+		return false
+	}
+
+	s := ao.history[len(ao.history)-1]
+	ao.history = ao.history[:len(ao.history)-1]
+
+	after := ao.capture(s)
+	after.changes = s.changes
+	for i := len(s.changes) - 1; i >= 0; i-- {
+		// Changes are undone in the opposite order they were made, so this can't fail
+		_ = ao.world.Undo(s.changes[i])
+	}
+	ao.restore(s)
+	ao.undone = append(ao.undone, after)
+
+	return true
+}
+
+// JumpToTurn steps back or forward until the end of the given turn. It stops before that when
+// the history doesn't go back that far, or when the simulation stops going forward.
+func (ao *AlienOrchestrator) JumpToTurn(turn int, stop StopConditions) {
+	for (ao.turn > turn || (ao.turn == turn && ao.inTurn)) && ao.StepBack() {
+	}
+
+	for ao.turn < turn || (ao.turn == turn && ao.inTurn) {
+		if ao.Stopped(stop) != "" {
+			return
+		}
+		ao.StepAlien()
+	}
+}
+
+// Turn returns the current turn, which is 0 until the first one starts.
+func (ao *AlienOrchestrator) Turn() int {
+	return ao.turn
+}
+
+// redo redoes the last undone step.
+func (ao *AlienOrchestrator) redo() {
+	s := ao.undone[len(ao.undone)-1]
+	ao.undone = ao.undone[:len(ao.undone)-1]
+
+	before := ao.capture(s)
+	for _, c := range s.changes {
+		// The World is back where the change was made, so this can't fail either
+		redone, _ := ao.world.Redo(c)
+		before.changes = append(before.changes, redone)
+	}
+	ao.restore(s)
+	ao.history = append(ao.history, before)
+}
+
+// beginStep starts recording the changes made by a step, if history is kept.
+func (ao *AlienOrchestrator) beginStep() {
+	if ao.historySize < 1 {
+		return
+	}
+
+	ao.recording = &step{
+		turn:      ao.turn,
+		cursor:    ao.cursor,
+		inTurn:    ao.inTurn,
+		aliens:    copyAliens(ao.Aliens),
+		draws:     ao.source.draws,
+		states:    make(map[*Alien]alienState),
+		positions: make(map[string][]*Alien),
+		damage:    make(map[string]int),
+	}
+}
+
+// endStep adds the recorded step to the history, forgetting the oldest one if it's full.
+func (ao *AlienOrchestrator) endStep() {
+	if ao.recording == nil {
+		return
+	}
+
+	ao.history = append(ao.history, ao.recording)
+	if len(ao.history) > ao.historySize {
+		copy(ao.history, ao.history[1:])
+		ao.history = ao.history[:len(ao.history)-1]
+	}
+	ao.recording = nil
+}
+
+// touchAlien records the state of an alien before the step being recorded changes it.
+func (ao *AlienOrchestrator) touchAlien(a *Alien) {
+	if ao.recording == nil {
+		return
+	}
+	if _, ok := ao.recording.states[a]; !ok {
+		ao.recording.states[a] = stateOf(a)
+	}
+}
+
+// touchCity records the aliens in a city before the step being recorded changes them.
+func (ao *AlienOrchestrator) touchCity(city string) {
+	if ao.recording == nil {
+		return
+	}
+	if _, ok := ao.recording.positions[city]; !ok {
+		ao.recording.positions[city] = copyAliens(ao.positions[city])
+	}
+}
+
+// touchDamage records the hit points lost by a city before the step being recorded damages it.
+func (ao *AlienOrchestrator) touchDamage(city string) {
+	if ao.recording == nil {
+		return
+	}
+	if _, ok := ao.recording.damage[city]; !ok {
+		ao.recording.damage[city] = ao.damage[city]
+	}
+}
+
+// touchWorld records a change made to the World by the step being recorded.
+func (ao *AlienOrchestrator) touchWorld(c world.Change) {
+	if ao.recording == nil {
+		return
+	}
+	ao.recording.changes = append(ao.recording.changes, c)
+}
+
+// capture returns the current values of the state recorded in s.
+func (ao *AlienOrchestrator) capture(s *step) *step {
+	c := &step{
+		turn:      ao.turn,
+		cursor:    ao.cursor,
+		inTurn:    ao.inTurn,
+		aliens:    copyAliens(ao.Aliens),
+		draws:     ao.draws(),
+		states:    make(map[*Alien]alienState, len(s.states)),
+		positions: make(map[string][]*Alien, len(s.positions)),
+		damage:    make(map[string]int, len(s.damage)),
+	}
+	for a := range s.states {
+		c.states[a] = stateOf(a)
+	}
+	for city := range s.positions {
+		c.positions[city] = copyAliens(ao.positions[city])
+	}
+	for city := range s.damage {
+		c.damage[city] = ao.damage[city]
+	}
+
+	return c
+}
+
+// restore sets the state to the values recorded in s, except for the World.
+func (ao *AlienOrchestrator) restore(s *step) {
+	ao.turn = s.turn
+	ao.cursor = s.cursor
+	ao.inTurn = s.inTurn
+	ao.Aliens = copyAliens(s.aliens)
+	ao.undoneDraws = s.draws
+	ao.checkIsolated, ao.components = true, nil
+
+	for a, state := range s.states {
+		a.Position = state.position
+		a.Destination = state.destination
+		a.travel, a.traveled = state.travel, state.traveled
+		a.isDeleted = state.deleted
+		a.isolated = state.isolated
+		if avoid, ok := a.Strategy.(*AvoidRecent); ok {
+			avoid.recent = append([]*world.City(nil), state.recent...)
+		}
+	}
+
+	for city, aliens := range s.positions {
+		if len(aliens) == 0 {
+			delete(ao.positions, city)
+			continue
+		}
+		ao.positions[city] = copyAliens(aliens)
+	}
+
+	for city, damage := range s.damage {
+		if damage == 0 {
+			delete(ao.damage, city)
+			continue
+		}
+		ao.damage[city] = damage
+	}
+}
+
+// draws returns the amount of values drawn from the random source to reach the current state,
+// which are less than the values drawn so far while there are undone steps.
+func (ao *AlienOrchestrator) draws() uint64 {
+	if len(ao.undone) > 0 {
+		return ao.undoneDraws
+	}
+	return ao.source.draws
+}
+
+func stateOf(a *Alien) alienState {
+	state := alienState{
+		position:    a.Position,
+		destination: a.Destination,
+		travel:      a.travel,
+		traveled:    a.traveled,
+		deleted:     a.isDeleted,
+		isolated:    a.isolated,
+	}
+	if avoid, ok := a.Strategy.(*AvoidRecent); ok {
+		state.recent = append([]*world.City(nil), avoid.recent...)
+	}
+
+	return state
+}
+
+// copyAliens returns a copy of a slice of aliens, so that appending to either of them
+// doesn't change the other one.
+func copyAliens(aliens []*Alien) []*Alien {
+	return append([]*Alien(nil), aliens...)
+}
@@ -0,0 +1,236 @@
+package alien
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/santihernandezc/alien-invasion/world"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStepBack(t *testing.T) {
+	worldDef := "A north=B east=C\nB east=D\nC north=D south=E\nD east=F\nE west=F\nF south=G\nG south=D"
+	// The same map, with roads taking up to three turns and cities withstanding fights
+	weightedDef := `[
+		{"name": "A", "neighbors": [{"name": "B", "length": 2}, "C"]},
+		{"name": "B", "neighbors": [{"name": "D", "length": 3}]},
+		{"name": "C", "neighbors": ["D", {"name": "E", "length": 2}]},
+		{"name": "D", "population": 1000, "hit_points": 3, "defense": 0.5, "neighbors": ["F"]},
+		{"name": "E", "neighbors": [{"name": "F", "length": 2.5}]},
+		{"name": "F", "hit_points": 2, "neighbors": ["G"]},
+		{"name": "G", "neighbors": [{"name": "D", "length": 2}]}
+	]`
+
+	keepCities := DefaultRules()
+	keepCities.DestroyCity = false
+	keepCities.SurvivalProbability = 0.5
+
+	simultaneous := DefaultRules()
+	simultaneous.TurnModel = TurnSimultaneous
+	simultaneous.CrossingFights = true
+
+	traveling := DefaultRules()
+	traveling.Speed = 1
+
+	travelingSimultaneous := simultaneous
+	travelingSimultaneous.Speed = 1
+
+	roadsLost := keepCities
+	roadsLost.RoadsLost = 1
+
+	roadsBlocked := keepCities
+	roadsBlocked.RoadsLost = 2
+	roadsBlocked.BlockTurns = 3
+
+	roadsBlockedSimultaneous := simultaneous
+	roadsBlockedSimultaneous.DestroyCity = false
+	roadsBlockedSimultaneous.RoadsLost = 1
+	roadsBlockedSimultaneous.BlockTurns = 2
+
+	retireIsolated := roadsLost
+	retireIsolated.RetireIsolated = true
+
+	newOrchestrator := func(tt *testing.T, seed int64, rules Rules) *AlienOrchestrator {
+		var w *world.World
+		var err error
+		if rules.Speed > 0 {
+			w, err = world.NewFromBytes([]byte(weightedDef), false)
+		} else {
+			w, err = world.NewFromReader(strings.NewReader(worldDef), false)
+		}
+		if !assert.NoError(tt, err) {
+			tt.FailNow()
+		}
+		ao, err := NewOrchestrator(8, seed, w, rules, nopLogger)
+		if !assert.NoError(tt, err) {
+			tt.FailNow()
+		}
+		if !assert.NoError(tt, AssignStrategies(ao.Aliens, []string{StrategyAvoidRecent, StrategyRandomWalk})) {
+			tt.FailNow()
+		}
+		return ao
+	}
+
+	// state returns everything a snapshot saves
+	state := func(tt *testing.T, ao *AlienOrchestrator) snapshot {
+		var buf bytes.Buffer
+		if !assert.NoError(tt, ao.Snapshot(&buf)) {
+			tt.FailNow()
+		}
+		var s snapshot
+		if !assert.NoError(tt, json.Unmarshal(buf.Bytes(), &s)) {
+			tt.FailNow()
+		}
+		return s
+	}
+
+	tests := []struct {
+		name  string
+		rules Rules
+	}{
+		{"default rules", DefaultRules()},
+		{"cities kept", keepCities},
+		{"simultaneous turns", simultaneous},
+		{"roads taking turns", traveling},
+		{"roads taking turns, simultaneous turns", travelingSimultaneous},
+		{"roads lost", roadsLost},
+		{"roads blocked", roadsBlocked},
+		{"roads blocked, simultaneous turns", roadsBlockedSimultaneous},
+		{"isolated aliens retired", retireIsolated},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			for seed := int64(1); seed <= 10; seed++ {
+				stop := StopConditions{MaxTurns: 30}
+				var expected []Event
+				plain := newOrchestrator(tt, seed, test.rules)
+				plain.Subscribe(SubscriberFunc(func(e Event) { expected = append(expected, e) }))
+				plain.Run(stop)
+
+				var events []Event
+				ao := newOrchestrator(tt, seed, test.rules)
+				ao.Subscribe(SubscriberFunc(func(e Event) { events = append(events, e) }))
+				ao.KeepHistory(100)
+
+				// Every step undone leads back to the state before it
+				var states []snapshot
+				for i := 0; i < 12 && ao.Stopped(stop) == ""; i++ {
+					states = append(states, state(tt, ao))
+					ao.StepAlien()
+				}
+				last := state(tt, ao)
+				for i := len(states) - 1; i >= 0; i-- {
+					assert.True(tt, ao.StepBack())
+					assert.Equal(tt, states[i], state(tt, ao), "seed %d, step %d", seed, i)
+				}
+				assert.False(tt, ao.StepBack())
+
+				// Steps are redone before moving on, so the simulation goes on as it would have
+				for range states {
+					ao.StepAlien()
+				}
+				assert.Equal(tt, last, state(tt, ao), "seed %d", seed)
+
+				ao.Run(stop)
+				assert.Equal(tt, expected, events, "seed %d", seed)
+			}
+		})
+	}
+
+	for _, test := range tests {
+		t.Run(test.name+", saved after stepping back", func(tt *testing.T) {
+			for seed := int64(1); seed <= 10; seed++ {
+				stop := StopConditions{MaxTurns: 30}
+				ao := newOrchestrator(tt, seed, test.rules)
+				ao.KeepHistory(100)
+				for i := 0; i < 12; i++ {
+					ao.StepAlien()
+				}
+				for i := 0; i < 5; i++ {
+					ao.StepBack()
+				}
+
+				var buf bytes.Buffer
+				if !assert.NoError(tt, ao.Snapshot(&buf)) {
+					return
+				}
+				restored, err := Restore(&buf, nopLogger)
+				if !assert.NoError(tt, err) {
+					return
+				}
+
+				// The restored simulation goes on like the original one would have
+				var expected, events []Event
+				ao.Subscribe(SubscriberFunc(func(e Event) { expected = append(expected, e) }))
+				restored.Subscribe(SubscriberFunc(func(e Event) { events = append(events, e) }))
+				ao.Run(stop)
+				restored.Run(stop)
+
+				// Redone steps don't emit their events again
+				assert.Equal(tt, expected, events[len(events)-len(expected):], "seed %d", seed)
+				assert.Equal(tt, state(tt, ao), state(tt, restored), "seed %d", seed)
+			}
+		})
+	}
+
+	t.Run("history is bounded", func(tt *testing.T) {
+		ao := newOrchestrator(tt, 1, keepCities)
+		ao.KeepHistory(3)
+		for i := 0; i < 5; i++ {
+			ao.StepAlien()
+		}
+
+		for i := 0; i < 3; i++ {
+			assert.True(tt, ao.StepBack())
+		}
+		assert.False(tt, ao.StepBack())
+		assert.Equal(tt, 1, ao.Turn())
+		assert.Equal(tt, 2, ao.cursor)
+	})
+
+	t.Run("history isn't kept by default", func(tt *testing.T) {
+		ao := newOrchestrator(tt, 1, DefaultRules())
+		ao.StepAlien()
+
+		assert.False(tt, ao.StepBack())
+	})
+}
+
+func TestJumpToTurn(t *testing.T) {
+	w, err := world.NewFromReader(strings.NewReader("A east=B\nB east=C\nC east=D\nD east=A"), false)
+	if !assert.NoError(t, err) {
+		return
+	}
+	rules := DefaultRules()
+	rules.DestroyCity = false
+	rules.SurvivalProbability = 1
+	ao, err := NewOrchestrator(3, 1, w, rules, nopLogger)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ao.KeepHistory(100)
+	stop := StopConditions{MaxTurns: 10}
+
+	tests := []struct {
+		name     string
+		turn     int
+		expected int
+	}{
+		{"forward", 5, 5},
+		{"backward", 2, 2},
+		{"before the first turn", 0, 0},
+		{"beyond the stop conditions", 20, 10},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			ao.JumpToTurn(test.turn, stop)
+
+			assert.Equal(tt, test.expected, ao.Turn())
+			assert.False(tt, ao.inTurn)
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package alien
+
+import (
+	"github.com/santihernandezc/alien-invasion/world"
+	"github.com/santihernandezc/alien-invasion/world/analysis"
+)
+
+// isolate finds the aliens that can't meet any other alien anymore, because no other alien can
+// get to any of the cities they can get to, and retires them if the rules say so. Aliens on a road
+// start from the city they're going to, and the ones going to a destroyed city are left out, since
+// they'll be trapped in its ruins. Aliens are only checked once, after they're isolated.
+func (ao *AlienOrchestrator) isolate() {
+	// Aliens can only be cut off by losing other aliens, by losing cities or roads, which splits
+	// the components, or, in directed Worlds, by taking a road into a component they can't come back from
+	changed := ao.checkIsolated
+	if ao.components == nil {
+		ao.components = newComponents(ao.world, ao.reopening())
+		ao.starts = make(map[*Alien]int, len(ao.Aliens))
+	}
+	ao.checkIsolated = false
+
+	var starting []*Alien
+	for _, a := range ao.Aliens {
+		component, ok := ao.components.of[start(a)]
+		if !ok {
+			continue
+		}
+
+		if previous, ok := ao.starts[a]; !ok || previous != component {
+			ao.starts[a] = component
+			changed = true
+		}
+		starting = append(starting, a)
+	}
+	if !changed {
+		return
+	}
+
+	// Count how many aliens can get to each component
+	reached := make([]int, len(ao.components.next))
+	for _, a := range starting {
+		for _, c := range ao.components.reach(ao.starts[a]) {
+			reached[c]++
+		}
+	}
+
+	var isolated []*Alien
+	for _, a := range starting {
+		if a.isolated || !alone(ao.components.reach(ao.starts[a]), reached) {
+			continue
+		}
+
+		ao.touchAlien(a)
+		a.isolated = true
+		isolated = append(isolated, a)
+		ao.emit(AlienIsolated{Turn: ao.turn, AlienID: a.ID, City: start(a).Name, Retired: ao.rules.RetireIsolated})
+
+		if ao.rules.RetireIsolated && !a.Traveling() {
+			ao.removeAlienFromCity(a.Position.Name, a)
+		}
+	}
+
+	if ao.rules.RetireIsolated && len(isolated) > 0 {
+		ao.deleteAliens(isolated)
+	}
+}
+
+// start returns the city an alien starts from to meet other aliens, which is the one it's going to
+// if it's on a road.
+func start(a *Alien) *world.City {
+	if a.Traveling() {
+		return a.Destination
+	}
+	return a.Position
+}
+
+// reopening returns the blocked roads that will open again, because both their cities are standing.
+func (ao *AlienOrchestrator) reopening() []world.Road {
+	var roads []world.Road
+	for _, b := range ao.world.BlockedRoads() {
+		if ao.world.Cities[b.From.Name] == b.From && ao.world.Cities[b.To.Name] == b.To {
+			roads = append(roads, b.Road)
+		}
+	}
+
+	return roads
+}
+
+// components are the strong components of a World, where aliens can get from any city to the
+// rest, and the roads between them, which only lead to components that can't lead back.
+type components struct {
+	// of has the index of the component of each standing city
+	of map[*world.City]int
+	// next has the components each component has roads to
+	next [][]int
+	// reachable has the components that can be reached from each component, found when needed
+	reachable map[int][]int
+}
+
+// newComponents returns the components of a World, counting the given blocked roads.
+func newComponents(w *world.World, blocked []world.Road) *components {
+	strong := analysis.StrongComponentsWith(w, blocked)
+	c := components{
+		of:        make(map[*world.City]int, len(w.Cities)),
+		next:      make([][]int, len(strong)),
+		reachable: make(map[int][]int),
+	}
+	for i, component := range strong {
+		for _, city := range component {
+			c.of[city] = i
+		}
+	}
+
+	// Roads of undirected Worlds never leave their component
+	if !w.Directed() {
+		return &c
+	}
+
+	link := func(from, to *world.City) {
+		i, ok := c.of[from]
+		if !ok {
+			return
+		}
+		if j, ok := c.of[to]; ok && i != j {
+			c.next[i] = append(c.next[i], j)
+		}
+	}
+	for _, city := range w.Cities {
+		for _, n := range city.Neighbors {
+			link(city, n)
+		}
+	}
+	for _, r := range blocked {
+		link(r.From, r.To)
+	}
+
+	return &c
+}
+
+// reach returns the components that can be reached from a component, including itself.
+func (c *components) reach(from int) []int {
+	if reached, ok := c.reachable[from]; ok {
+		return reached
+	}
+
+	visited := map[int]bool{from: true}
+	reached := []int{from}
+	for i := 0; i < len(reached); i++ {
+		for _, next := range c.next[reached[i]] {
+			if !visited[next] {
+				visited[next] = true
+				reached = append(reached, next)
+			}
+		}
+	}
+
+	c.reachable[from] = reached
+	return reached
+}
+
+// alone reports whether a single alien can reach each of the given components.
+func alone(components []int, reached []int) bool {
+	for _, c := range components {
+		if reached[c] > 1 {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,116 @@
+package alien
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/santihernandezc/alien-invasion/world"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsolate(t *testing.T) {
+	retire := DefaultRules()
+	retire.RetireIsolated = true
+
+	t.Run("aliens alone in their component are isolated", func(tt *testing.T) {
+		ao, _ := newOrchestratorWithAliens(tt, "A east=B\nC east=D", DefaultRules(), "A", "C", "D")
+
+		var events []Event
+		ao.Subscribe(SubscriberFunc(func(e Event) {
+			events = append(events, e)
+		}))
+		ao.StepAlien()
+		ao.StepAlien()
+
+		assert.Equal(tt, []Event{
+			AlienMoved{Turn: 1, AlienID: 1, From: "A", To: "B"},
+			AlienIsolated{Turn: 1, AlienID: 1, City: "B"},
+			AlienMoved{Turn: 1, AlienID: 2, From: "C", To: "D"},
+			AliensMet{Turn: 1, AlienID: 2, RivalIDs: []int{3}, City: "D"},
+			AliensKilled{Turn: 1, City: "D", AlienIDs: []int{2, 3}},
+			CityDestroyed{Turn: 1, City: "D", AlienIDs: []int{2, 3}},
+		}, events)
+		assert.True(tt, ao.Aliens[0].isolated)
+		assert.Equal(tt, 1, ao.AliensIn("B"))
+	})
+
+	t.Run("isolated aliens are retired", func(tt *testing.T) {
+		ao, _ := newOrchestratorWithAliens(tt, "A east=B\nC east=D", retire, "A", "C", "D")
+
+		var events []Event
+		ao.Subscribe(SubscriberFunc(func(e Event) {
+			events = append(events, e)
+		}))
+		ao.StepAlien()
+
+		assert.Equal(tt, []Event{
+			AlienMoved{Turn: 1, AlienID: 1, From: "A", To: "B"},
+			AlienIsolated{Turn: 1, AlienID: 1, City: "B", Retired: true},
+		}, events)
+		assert.Equal(tt, []int{2, 3}, alienIDs(ao.Aliens))
+		assert.Equal(tt, 0, ao.AliensIn("B"))
+	})
+
+	t.Run("lost cities split the components found before", func(tt *testing.T) {
+		ao, _ := newOrchestratorWithAliens(tt, "A east=B\nB east=C\nC east=D", DefaultRules(), "D", "A", "B", "A")
+
+		var events []Event
+		ao.Subscribe(SubscriberFunc(func(e Event) {
+			events = append(events, e)
+		}))
+		ao.StepAlien()
+		ao.StepAlien()
+
+		assert.Equal(tt, []Event{
+			AlienMoved{Turn: 1, AlienID: 1, From: "D", To: "C"},
+			AlienMoved{Turn: 1, AlienID: 2, From: "A", To: "B"},
+			AliensMet{Turn: 1, AlienID: 2, RivalIDs: []int{3}, City: "B"},
+			AliensKilled{Turn: 1, City: "B", AlienIDs: []int{2, 3}},
+			CityDestroyed{Turn: 1, City: "B", AlienIDs: []int{2, 3}},
+			AlienIsolated{Turn: 1, AlienID: 1, City: "C"},
+			AlienIsolated{Turn: 1, AlienID: 4, City: "A"},
+		}, events)
+	})
+
+	t.Run("blocked roads that will open keep aliens together", func(tt *testing.T) {
+		ao, w := newOrchestratorWithAliens(tt, "A east=B", retire, "A", "B")
+		_, err := w.BlockRoad(w.Cities["A"], w.Cities["B"], 2)
+		if !assert.NoError(tt, err) {
+			return
+		}
+		ao.StepRound()
+
+		assert.Equal(tt, []int{1, 2}, alienIDs(ao.Aliens))
+		assert.False(tt, ao.Aliens[0].isolated)
+	})
+
+	t.Run("aliens can't leave a sink in directed worlds", func(tt *testing.T) {
+		// Alien 1 can meet alien 2 in C, unless it goes to B, which leaves both of them alone
+		w, err := world.NewFromReader(strings.NewReader("A east=B north=C\nD east=C\nC east=D"), true)
+		if !assert.NoError(tt, err) {
+			return
+		}
+		ao, err := NewOrchestrator(0, 0, w, retire, nopLogger)
+		if !assert.NoError(tt, err) {
+			return
+		}
+		for i, city := range []string{"A", "D"} {
+			a := &Alien{ID: i + 1, Position: w.Cities[city]}
+			ao.Aliens = append(ao.Aliens, a)
+			ao.addAlienToCity(city, a)
+		}
+
+		var events []Event
+		ao.Subscribe(SubscriberFunc(func(e Event) {
+			events = append(events, e)
+		}))
+		ao.StepAlien()
+
+		assert.Equal(tt, []Event{
+			AlienMoved{Turn: 1, AlienID: 1, From: "A", To: "B"},
+			AlienIsolated{Turn: 1, AlienID: 1, City: "B", Retired: true},
+			AlienIsolated{Turn: 1, AlienID: 2, City: "D", Retired: true},
+		}, events)
+		assert.Equal(tt, 0, len(ao.Aliens))
+	})
+}
@@ -0,0 +1,72 @@
+package alien
+
+import (
+	"sort"
+
+	"github.com/santihernandezc/alien-invasion/world"
+)
+
+// Losses are the population and the strategic value lost by the cities of the World.
+type Losses struct {
+	Population      int
+	TotalPopulation int
+	Value           float64
+	TotalValue      float64
+	// Cities has the losses of every city that was damaged or destroyed, sorted by name
+	Cities []CityLosses
+}
+
+// CityLosses are the losses of a single city. Damaged cities lose a share of their population
+// and value for each hit point lost, destroyed cities lose all of it.
+type CityLosses struct {
+	City      string
+	Destroyed bool
+	// Damage is the amount of hit points lost
+	Damage     int
+	Population int
+	Value      float64
+}
+
+// Losses returns what the cities lost so far.
+func (ao *AlienOrchestrator) Losses() Losses {
+	var losses Losses
+	add := func(city *world.City, destroyed bool) {
+		attributes := city.Attributes
+		losses.TotalPopulation += attributes.Population
+		losses.TotalValue += attributes.Value
+
+		damage := ao.damage[city.Name]
+		if destroyed {
+			damage = attributes.MaxHitPoints()
+		}
+		if damage == 0 {
+			return
+		}
+
+		cl := CityLosses{
+			City:       city.Name,
+			Destroyed:  destroyed,
+			Damage:     damage,
+			Population: populationLost(attributes, damage),
+			Value:      attributes.Value * float64(damage) / float64(attributes.MaxHitPoints()),
+		}
+		losses.Population += cl.Population
+		losses.Value += cl.Value
+		losses.Cities = append(losses.Cities, cl)
+	}
+
+	for _, city := range ao.world.Cities {
+		add(city, false)
+	}
+	for _, city := range ao.world.DestroyedCities {
+		add(city, true)
+	}
+	sort.Slice(losses.Cities, func(i, j int) bool { return losses.Cities[i].City < losses.Cities[j].City })
+
+	return losses
+}
+
+// populationLost returns the population a city loses after losing the given amount of hit points.
+func populationLost(attributes world.Attributes, damage int) int {
+	return attributes.Population * damage / attributes.MaxHitPoints()
+}
@@ -0,0 +1,31 @@
+package alien
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLosses(t *testing.T) {
+	ao, w := newOrchestratorWithAliens(t, `[
+		{"name": "A", "population": 900, "hit_points": 3, "value": 6, "neighbors": ["B"]},
+		{"name": "B", "population": 100, "value": 4, "neighbors": ["C"]},
+		{"name": "C", "neighbors": []}
+	]`, DefaultRules())
+
+	assert.Equal(t, Losses{TotalPopulation: 1000, TotalValue: 10}, ao.Losses())
+
+	ao.damage["A"] = 1
+	w.DeleteCityAndRoads(w.Cities["B"])
+
+	assert.Equal(t, Losses{
+		Population:      400,
+		TotalPopulation: 1000,
+		Value:           6,
+		TotalValue:      10,
+		Cities: []CityLosses{
+			{City: "A", Damage: 1, Population: 300, Value: 2},
+			{City: "B", Destroyed: true, Damage: 1, Population: 100, Value: 4},
+		},
+	}, ao.Losses())
+}
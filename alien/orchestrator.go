@@ -3,9 +3,10 @@ package alien
 import (
 	"fmt"
 	"log"
+	"math"
 	"math/rand"
+	"time"
 
-	rl "github.com/gen2brain/raylib-go/raylib"
 	"github.com/santihernandezc/alien-invasion/world"
 )
 
@@ -17,10 +18,48 @@ type AlienOrchestrator struct {
 	// positions maps a city name with the aliens in that city
 	world     *world.World
 	positions map[string][]*Alien
-	log       *log.Logger
+	// damage maps a city name with the hit points it lost
+	damage map[string]int
+	log    *log.Logger
+	rules  Rules
+	// subscribers are notified of every event in the simulation
+	subscribers []Subscriber
+	// turn is the current turn of the simulation, starting at 1
+	turn int
+	// cursor is the index in Aliens of the next alien to move in the current turn
+	cursor int
+	// inTurn tells whether there are aliens left to move in the current turn
+	inTurn bool
+	// ended tells whether the end of the simulation was already emitted
+	ended bool
+	// started is when the first turn started
+	started time.Time
+	// rng is the source for every random decision taken by the orchestrator and its aliens,
+	// which draws its values from source
+	rng    *rand.Rand
+	source *countingSource
+	// history has the last steps, which can be undone, up to historySize of them,
+	// and undone the steps undone, which are redone before moving the aliens again
+	history     []*step
+	undone      []*step
+	historySize int
+	// undoneDraws is the amount of values drawn from rng to reach the current state while steps are undone
+	undoneDraws uint64
+	// recording is the step being recorded, only while history is kept
+	recording *step
+	// checkIsolated tells whether aliens were lost since the last check for isolated aliens
+	checkIsolated bool
+	// components are the components of the World used to check for isolated aliens, nil once
+	// cities or roads are lost until the next check, and starts the component each alien started from
+	components *components
+	starts     map[*Alien]int
 }
 
-func NewOrchestrator(amount int, rngSeed int64, w *world.World, alienTexture rl.Texture2D, log *log.Logger) (*AlienOrchestrator, error) {
+// NewOrchestrator places the given amount of aliens in random cities of the World,
+// which fight according to the given rules.
+// Every random decision is taken from a source seeded with rngSeed, so the same
+// seed, World, rules and amount of aliens always lead to the same simulation.
+func NewOrchestrator(amount int, rngSeed int64, w *world.World, rules Rules, log *log.Logger) (*AlienOrchestrator, error) {
 	// Prevent panics
 	if w == nil {
 		return nil, fmt.Errorf("invalid World value: <nil>")
@@ -31,33 +70,38 @@ func NewOrchestrator(amount int, rngSeed int64, w *world.World, alienTexture rl.
 	if len(w.Cities) == 0 {
 		return nil, fmt.Errorf("invalid World value: 0 cities")
 	}
+	if err := rules.validate(); err != nil {
+		return nil, err
+	}
 
+	source := newCountingSource(rngSeed, 0)
 	alienOrchestrator := AlienOrchestrator{
 		Aliens:    make([]*Alien, 0, amount),
 		positions: make(map[string][]*Alien, len(w.Cities)),
+		damage:    make(map[string]int),
 		world:     w,
 		log:       log,
+		rules:     rules,
+		source:    source,
+		rng:       rand.New(source),
+		// Aliens might start where they can't meet any other alien
+		checkIsolated: true,
 	}
 
-	// Make a slice to choose random cities as starting positions
-	var cities []*world.City
-	for _, city := range w.Cities {
-		cities = append(cities, city)
-	}
+	// Events are logged by default
+	alienOrchestrator.Subscribe(NewLogSubscriber(log))
 
-	// To make things more random-like, use the seed
-	rand.Seed(rngSeed)
+	// Make a slice to choose random cities as starting positions.
+	// Cities are sorted so the same seed always leads to the same positions.
+	cities := w.SortedCities()
 
 	// Choose a random city for each alien.
 	// Start from 1 instead of 0 to use the same value for the alien's ID.
 	for i := 1; i <= amount; i++ {
-		city := cities[rand.Intn(len(cities))]
+		city := cities[alienOrchestrator.rng.Intn(len(cities))]
 		alien := Alien{
-			ID:           i,
-			City:         city,
-			Position:     rl.NewVector2(float32(city.Position.X), float32(city.Position.Y)),
-			NextPosition: rl.NewVector2(float32(city.Position.X), float32(city.Position.Y)),
-			Texture:      alienTexture,
+			ID:       i,
+			Position: city,
 		}
 		alienOrchestrator.Aliens = append(alienOrchestrator.Aliens, &alien)
 		alienOrchestrator.positions[city.Name] = append(alienOrchestrator.positions[city.Name], &alien)
@@ -66,99 +110,243 @@ func NewOrchestrator(amount int, rngSeed int64, w *world.World, alienTexture rl.
 	return &alienOrchestrator, nil
 }
 
-func (ao *AlienOrchestrator) UnleashAliens(maxMovements int) {
-	for i := 0; i < maxMovements; i++ {
-		// If there are no aliens left, the simulation is over
-		if len(ao.Aliens) < 1 {
-			return
+// Run moves the aliens round after round until the simulation stops,
+// when there are no aliens left or because of the given conditions, and then ends it.
+func (ao *AlienOrchestrator) Run(stop StopConditions) Result {
+	for {
+		if reason := ao.Stopped(stop); reason != "" {
+			return ao.End(reason)
 		}
+		ao.StepRound()
+	}
+}
+
+// End ends the simulation for the given reason and returns its result.
+// The end of the simulation is only emitted on the first call.
+func (ao *AlienOrchestrator) End(reason StopReason) Result {
+	if !ao.ended {
+		ao.ended = true
+		ao.emit(SimulationEnded{Turn: ao.turn, AliensLeft: len(ao.Aliens), Reason: reason})
+	}
+
+	destroyed := make([]string, len(ao.world.DestroyedCities))
+	for i, city := range ao.world.DestroyedCities {
+		destroyed[i] = city.Name
+	}
+
+	return Result{
+		Reason:          reason,
+		Turns:           ao.turn,
+		Survivors:       alienIDs(ao.Aliens),
+		DestroyedCities: destroyed,
+		Losses:          ao.Losses(),
+		World:           ao.world,
+	}
+}
 
-		for _, alien := range ao.Aliens {
-			// Check if the alien was killed or stuck in the current loop
-			if alien.isDeleted {
-				continue
-			}
-
-			// Make the alien move
-			prevPos := alien.City.Name
-			if ok := alien.move(); !ok {
-				ao.log.Printf("🚷 Alien %d is trapped forever in %s", alien.ID, alien.City.Name)
-				ao.deleteAliens([]*Alien{alien})
-				continue
-			}
-
-			// Remove it from the city it was previously in
-			newPos := alien.City.Name
-			ao.removeAlienFromCity(prevPos, alien)
-			ao.log.Printf("👾 Alien %d moved from %s to %s", alien.ID, prevPos, newPos)
-
-			// Check if there's another alien in the new position
-			rivalAliens, ok := ao.positions[newPos]
-			if ok && len(rivalAliens) > 0 {
-				// If two aliens find each other, the city gets destroyed and the aliens die.
-				ao.log.Printf("👀 Alien %d found Alien %d in %s", alien.ID, rivalAliens[0].ID, newPos)
-				ao.world.DeleteCityAndRoads(alien.City)
-				ao.log.Printf("💥 %s has been destroyed by Alien %d and Alien %d", newPos, alien.ID, rivalAliens[0].ID)
-
-				// Since the city is destroyed, other aliens can't go to or through it
-				aliensToEliminate := append(rivalAliens, alien)
-
-				if len(rivalAliens) > 1 {
-					for _, ra := range rivalAliens[1:] {
-						ao.log.Printf("🚷 Alien %d is trapped forever in the ruins of %s", ra.ID, alien.City.Name)
-					}
-				}
-
-				ao.deleteCityAndAliens(aliensToEliminate, newPos)
-			}
-
-			// After checking for other aliens, add alien to city
-			ao.addAlienToCity(newPos, alien)
+// StepRound moves every alien that didn't move yet in the current turn, or every alien
+// in a new turn if they all moved already.
+func (ao *AlienOrchestrator) StepRound() {
+	if len(ao.Aliens) < 1 {
+		return
+	}
+
+	if ao.rules.TurnModel == TurnSimultaneous {
+		if len(ao.undone) > 0 {
+			ao.redo()
+			return
 		}
+
+		ao.beginStep()
+		ao.startTurn()
+		ao.moveSimultaneously()
+		ao.isolate()
+		ao.endStep()
+		return
+	}
+
+	ao.StepAlien()
+	for ao.inTurn {
+		ao.StepAlien()
 	}
 }
 
-func (ao *AlienOrchestrator) Step(alien *Alien) {
-	// Check if the alien was killed or stuck in the current loop
-	if alien.isDeleted {
+// StepAlien moves the next alien, in the order of Aliens. A new turn starts once every
+// alien moved in the previous one, regardless of the aliens deleted in between.
+// Aliens can't move on their own with the simultaneous turn model, so StepAlien moves all of them.
+func (ao *AlienOrchestrator) StepAlien() {
+	if len(ao.Aliens) < 1 {
 		return
 	}
 
-	// Make the alien move
-	prevPos := alien.City.Name
-	if ok := alien.move(); !ok {
-		ao.log.Printf("🚷 Alien %d is trapped forever in %s", alien.ID, alien.City.Name)
-		ao.deleteAliens([]*Alien{alien})
+	if len(ao.undone) > 0 {
+		ao.redo()
 		return
 	}
 
-	// Remove it from the city it was previously in
-	newPos := alien.City.Name
-	ao.removeAlienFromCity(prevPos, alien)
-	ao.log.Printf("👾 Alien %d moved from %s to %s", alien.ID, prevPos, newPos)
+	if ao.rules.TurnModel == TurnSimultaneous {
+		ao.StepRound()
+		return
+	}
+
+	ao.beginStep()
+	defer ao.endStep()
+	if !ao.inTurn {
+		ao.startTurn()
+		ao.inTurn = true
+	}
 
-	// Check if there's another alien in the new position
-	rivalAliens, ok := ao.positions[newPos]
-	if ok && len(rivalAliens) > 0 {
-		// If two aliens find each other, the city gets destroyed and the aliens die.
-		ao.log.Printf("👀 Alien %d found Alien %d in %s", alien.ID, rivalAliens[0].ID, newPos)
-		ao.world.DeleteCityAndRoads(alien.City)
-		ao.log.Printf("💥 %s has been destroyed by Alien %d and Alien %d", newPos, alien.ID, rivalAliens[0].ID)
+	alien := ao.Aliens[ao.cursor]
+	ao.cursor++
+	ao.moveAlien(alien)
+	ao.isolate()
+
+	// Start over once every alien moved
+	if ao.cursor >= len(ao.Aliens) {
+		ao.cursor = 0
+		ao.inTurn = false
+	}
+}
+
+func (ao *AlienOrchestrator) startTurn() {
+	if ao.started.IsZero() {
+		ao.started = time.Now()
+	}
+	ao.turn++
+
+	// Blocked roads open before any alien moves
+	c := ao.world.Tick()
+	ao.touchWorld(c)
+	for _, r := range c.Roads() {
+		ao.emit(RoadReopened{Turn: ao.turn, From: r.From.Name, To: r.To.Name})
+	}
+}
 
-		// Since the city is destroyed, other aliens can't go to or through it
-		aliensToEliminate := append(rivalAliens, alien)
+// moveAlien moves a single alien and resolves the fight in the city it arrives to.
+// Aliens on a road only arrive once they traveled it for long enough.
+func (ao *AlienOrchestrator) moveAlien(alien *Alien) {
+	ao.touchAlien(alien)
+	prevPos := alien.Position.Name
+	if alien.Traveling() {
+		if !alien.advance() {
+			return
+		}
+	} else if ao.waiting(alien) {
+		return
+	} else {
+		// Make the alien move
+		if ok := alien.move(ao, ao.rng); !ok {
+			ao.emit(AlienTrapped{Turn: ao.turn, AlienID: alien.ID, City: alien.Position.Name})
+			ao.deleteAliens([]*Alien{alien})
+			return
+		}
 
-		if len(rivalAliens) > 1 {
-			for _, ra := range rivalAliens[1:] {
-				ao.log.Printf("🚷 Alien %d is trapped forever in the ruins of %s", ra.ID, alien.City.Name)
-			}
+		// Remove it from the city it was previously in
+		ao.removeAlienFromCity(prevPos, alien)
+		if ao.depart(alien, prevPos) {
+			return
 		}
+	}
 
-		ao.deleteCityAndAliens(aliensToEliminate, newPos)
+	newPos := alien.Position.Name
+	if ao.arrivedInRuins(alien) {
+		return
+	}
+	ao.emit(AlienMoved{Turn: ao.turn, AlienID: alien.ID, From: prevPos, To: newPos})
+
+	// Check if there are other aliens in the new position
+	rivalAliens := ao.positions[newPos]
+	if len(rivalAliens) > 0 {
+		ao.emit(AliensMet{Turn: ao.turn, AlienID: alien.ID, RivalIDs: alienIDs(rivalAliens), City: newPos})
 	}
 
 	// After checking for other aliens, add alien to city
 	ao.addAlienToCity(newPos, alien)
+
+	// Aliens fight if there are enough of them in the city
+	if len(rivalAliens) > 0 && len(rivalAliens)+1 >= ao.rules.Threshold {
+		ao.fight([]*Alien{alien}, alien.Position)
+	}
+}
+
+// waiting reports whether an alien in a city has no roads to take, but will
+// have some once the blocked roads leaving the city open again.
+func (ao *AlienOrchestrator) waiting(alien *Alien) bool {
+	return !alien.Traveling() && len(alien.Position.Neighbors) == 0 && ao.world.HasBlockedRoads(alien.Position)
+}
+
+// depart puts an alien that left a city on the road, if it's long enough to take more than
+// a turn, and reports whether the alien is still on it after the current turn.
+func (ao *AlienOrchestrator) depart(alien *Alien, from string) (onRoad bool) {
+	if !alien.Traveling() {
+		return false
+	}
+
+	ao.emit(AlienDeparted{Turn: ao.turn, AlienID: alien.ID, From: from, To: alien.Destination.Name, Turns: alien.travel})
+	return !alien.advance()
+}
+
+// arrivedInRuins reports whether the city an alien arrived to was destroyed while it was on
+// the road, in which case the alien is trapped in the ruins.
+func (ao *AlienOrchestrator) arrivedInRuins(alien *Alien) bool {
+	if ao.world.Cities[alien.Position.Name] == alien.Position {
+		return false
+	}
+
+	ao.emit(AlienTrapped{Turn: ao.turn, AlienID: alien.ID, City: alien.Position.Name, InRuins: true})
+	ao.deleteAliens([]*Alien{alien})
+	return true
+}
+
+// TravelTime returns the amount of turns it takes to travel the road between two cities
+// at the speed set by the rules, which is at least one.
+func (ao *AlienOrchestrator) TravelTime(from, to *world.City) int {
+	if ao.rules.Speed <= 0 {
+		return 1
+	}
+
+	turns := int(math.Ceil(from.Length(to) / ao.rules.Speed))
+	if turns < 1 {
+		return 1
+	}
+	return turns
+}
+
+// AliensIn returns the amount of aliens in a city.
+func (ao *AlienOrchestrator) AliensIn(city string) int {
+	return len(ao.positions[city])
+}
+
+// AliensByCity maps the name of each city with aliens with their IDs.
+func (ao *AlienOrchestrator) AliensByCity() map[string][]int {
+	aliens := make(map[string][]int, len(ao.positions))
+	for city, as := range ao.positions {
+		if len(as) > 0 {
+			aliens[city] = alienIDs(as)
+		}
+	}
+
+	return aliens
+}
+
+// Subscribe registers a Subscriber to be notified of every event in the simulation.
+func (ao *AlienOrchestrator) Subscribe(s Subscriber) {
+	ao.subscribers = append(ao.subscribers, s)
+}
+
+func (ao *AlienOrchestrator) emit(e Event) {
+	for _, s := range ao.subscribers {
+		s.Notify(e)
+	}
+}
+
+func alienIDs(aliens []*Alien) []int {
+	ids := make([]int, len(aliens))
+	for i, a := range aliens {
+		ids[i] = a.ID
+	}
+
+	return ids
 }
 
 func (ao *AlienOrchestrator) deleteAliens(aliens []*Alien) {
@@ -167,24 +355,34 @@ func (ao *AlienOrchestrator) deleteAliens(aliens []*Alien) {
 	// First, check if the aliens are already in deleted state
 	for _, alien := range aliens {
 		if !alien.isDeleted {
+			ao.touchAlien(alien)
 			aliensToDelete[alien] = struct{}{}
 			alien.isDeleted = true
 		}
 	}
 
-	// Make a slice with the aliens that are still active
+	// Make a slice with the aliens that are still active, keeping the cursor
+	// pointing to the same alien when the ones before it are deleted
 	remainingAliens := make([]*Alien, 0, len(ao.Aliens)-len(aliensToDelete))
-	for _, alien := range ao.Aliens {
+	cursor := ao.cursor
+	for i, alien := range ao.Aliens {
 		if _, ok := aliensToDelete[alien]; !ok {
 			remainingAliens = append(remainingAliens, alien)
+		} else if i < ao.cursor {
+			cursor--
 		}
 	}
 
 	ao.Aliens = remainingAliens
+	ao.cursor = cursor
+	if len(aliensToDelete) > 0 {
+		ao.checkIsolated = true
+	}
 }
 
 func (ao *AlienOrchestrator) removeAlienFromCity(prevCity string, alien *Alien) {
 	// Filter out the alien from the slice corresponding to the previous city
+	ao.touchCity(prevCity)
 	newPositionSlice := make([]*Alien, 0, len(ao.positions)-1)
 	for _, a := range ao.positions[prevCity] {
 		if a.ID != alien.ID {
@@ -195,10 +393,146 @@ func (ao *AlienOrchestrator) removeAlienFromCity(prevCity string, alien *Alien)
 }
 
 func (ao *AlienOrchestrator) addAlienToCity(newCity string, alien *Alien) {
+	ao.touchCity(newCity)
 	ao.positions[newCity] = append(ao.positions[newCity], alien)
 }
 
+// fight resolves a fight between all the aliens in a city, started by the arriving ones.
+func (ao *AlienOrchestrator) fight(arriving []*Alien, city *world.City) {
+	// The arriving aliens go first, followed by the ones that were already there
+	isArriving := make(map[*Alien]bool, len(arriving))
+	fighters := make([]*Alien, 0, len(ao.positions[city.Name]))
+	for _, a := range arriving {
+		isArriving[a] = true
+		fighters = append(fighters, a)
+	}
+	for _, a := range ao.positions[city.Name] {
+		if !isArriving[a] {
+			fighters = append(fighters, a)
+		}
+	}
+
+	var dead, survivors []*Alien
+	for _, a := range fighters {
+		if ao.survives(isArriving[a]) {
+			survivors = append(survivors, a)
+		} else {
+			dead = append(dead, a)
+		}
+	}
+
+	if len(dead) > 0 {
+		ao.emit(AliensKilled{Turn: ao.turn, City: city.Name, AlienIDs: alienIDs(dead)})
+		ao.deleteAliens(dead)
+	}
+
+	if ao.withstands(city, fighters) {
+		ao.touchCity(city.Name)
+		ao.positions[city.Name] = survivors
+		return
+	}
+
+	// Since the city is destroyed, other aliens can't go to or through it
+	ao.touchWorld(ao.world.DestroyCity(city))
+	ao.components = nil
+	ao.emit(CityDestroyed{Turn: ao.turn, City: city.Name, AlienIDs: alienIDs(fighters)})
+
+	for _, a := range survivors {
+		ao.emit(AlienTrapped{Turn: ao.turn, AlienID: a.ID, City: city.Name, InRuins: true})
+	}
+	ao.deleteCityAndAliens(survivors, city.Name)
+}
+
+// withstands decides whether a city survives a fight between the given aliens. Unless its
+// defenses repel the fight, the city loses a hit point, and it's destroyed once it has none left.
+// Cities always survive if the rules don't destroy them, and lose roads whenever they're not destroyed.
+func (ao *AlienOrchestrator) withstands(city *world.City, fighters []*Alien) bool {
+	if !ao.rules.DestroyCity {
+		ao.loseRoads(city)
+		return true
+	}
+
+	attributes := city.Attributes
+	hitPoints := attributes.MaxHitPoints()
+
+	// Only use the random source if needed, so cities without defenses don't change the sequence
+	if attributes.Defense > 0 && ao.rng.Float64() < attributes.Defense {
+		ao.emit(CityDamaged{Turn: ao.turn, City: city.Name, AlienIDs: alienIDs(fighters), HitPoints: hitPoints - ao.damage[city.Name], Repelled: true})
+		return true
+	}
+
+	ao.touchDamage(city.Name)
+	ao.damage[city.Name]++
+	damage := ao.damage[city.Name]
+	if damage >= hitPoints {
+		return false
+	}
+
+	lost := populationLost(attributes, damage) - populationLost(attributes, damage-1)
+	ao.emit(CityDamaged{Turn: ao.turn, City: city.Name, AlienIDs: alienIDs(fighters), HitPoints: hitPoints - damage, PopulationLost: lost})
+	ao.loseRoads(city)
+	return true
+}
+
+// loseRoads takes from a city the amount of roads set by the rules, picked at random.
+func (ao *AlienOrchestrator) loseRoads(city *world.City) {
+	if ao.rules.RoadsLost == 0 {
+		return
+	}
+
+	// The city wasn't destroyed and the amounts were validated with the rules, so this can't fail
+	c, err := ao.world.DamageCity(city, ao.rules.RoadsLost, ao.rules.BlockTurns, ao.rng)
+	if err != nil {
+		return
+	}
+	ao.roadsLost(c)
+}
+
+// breakRoad destroys or blocks the road from one city to another, as set by the rules.
+func (ao *AlienOrchestrator) breakRoad(from, to *world.City) {
+	var c world.Change
+	var err error
+	if ao.rules.BlockTurns > 0 {
+		c, err = ao.world.BlockRoad(from, to, ao.rules.BlockTurns)
+	} else {
+		c, err = ao.world.DestroyRoad(from, to)
+	}
+
+	// The road might be gone already, e.g. when it was broken the other way
+	if err != nil {
+		return
+	}
+	ao.roadsLost(c)
+}
+
+// roadsLost records the roads lost in a change to the World and emits their events.
+func (ao *AlienOrchestrator) roadsLost(c world.Change) {
+	ao.touchWorld(c)
+	ao.components = nil
+	for _, r := range c.Roads() {
+		if ao.rules.BlockTurns > 0 {
+			ao.emit(RoadBlocked{Turn: ao.turn, From: r.From.Name, To: r.To.Name, Turns: ao.rules.BlockTurns})
+		} else {
+			ao.emit(RoadDestroyed{Turn: ao.turn, From: r.From.Name, To: r.To.Name})
+		}
+	}
+}
+
+// survives decides whether an alien survives a fight.
+func (ao *AlienOrchestrator) survives(isArriving bool) bool {
+	if isArriving && ao.rules.ArrivingSurvives {
+		return true
+	}
+
+	// Only use the random source if needed, so deterministic rules don't change the sequence
+	if ao.rules.SurvivalProbability > 0 {
+		return ao.rng.Float64() < ao.rules.SurvivalProbability
+	}
+	return false
+}
+
 func (ao *AlienOrchestrator) deleteCityAndAliens(alien []*Alien, cityName string) {
 	ao.deleteAliens(alien)
+	ao.touchCity(cityName)
 	delete(ao.positions, cityName)
 }
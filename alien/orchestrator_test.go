@@ -1,10 +1,12 @@
 package alien
 
 import (
+	"bytes"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/santihernandezc/alien-invasion/world"
@@ -69,7 +71,7 @@ func TestNewOrchestrator(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(tt *testing.T) {
-			ao, err := NewOrchestrator(test.n, 0, test.w, test.logger)
+			ao, err := NewOrchestrator(test.n, 0, test.w, DefaultRules(), test.logger)
 			if test.err != "" {
 				assert.EqualError(tt, err, test.err)
 				return
@@ -136,7 +138,7 @@ func TestDeleteAlien(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(tt *testing.T) {
-			ao, err := NewOrchestrator(test.n, 0, &testWorld, nopLogger)
+			ao, err := NewOrchestrator(test.n, 0, &testWorld, DefaultRules(), nopLogger)
 			assert.NoError(tt, err)
 
 			toDelete := ao.Aliens[:test.toDelete]
@@ -155,7 +157,7 @@ func TestDeleteAlien(t *testing.T) {
 	}
 }
 
-func TestUnleashAliens(t *testing.T) {
+func TestRun(t *testing.T) {
 	t.Run("when two aliens encounter, they kill each other and destroy the city", func(tt *testing.T) {
 		worldDef := "City1 south=City2\nCity2 north=City1"
 		w, err := world.NewFromReader(strings.NewReader(worldDef), false)
@@ -163,10 +165,10 @@ func TestUnleashAliens(t *testing.T) {
 			return
 		}
 
-		ao, err := NewOrchestrator(2, 0, w, nopLogger)
+		ao, err := NewOrchestrator(2, 0, w, DefaultRules(), nopLogger)
 		assert.NoError(tt, err)
 
-		ao.UnleashAliens(1)
+		ao.Run(StopConditions{MaxTurns: 1})
 
 		assert.Equal(tt, 0, len(ao.Aliens))
 		assert.Equal(tt, 1, len(w.Cities))
@@ -189,13 +191,232 @@ func TestUnleashAliens(t *testing.T) {
 			return
 		}
 
-		ao, err := NewOrchestrator(5, 0, w, nopLogger)
+		ao, err := NewOrchestrator(5, 0, w, DefaultRules(), nopLogger)
 		assert.NoError(tt, err)
 
-		ao.UnleashAliens(2)
+		ao.Run(StopConditions{MaxTurns: 2})
 
 		// Two aliens are killed, the other ones are trapped
 		assert.Equal(tt, 0, len(ao.Aliens))
 		assert.Equal(tt, 1, len(w.Cities))
 	})
 }
+
+func TestSeed(t *testing.T) {
+	worldDef := "A north=B east=C\nB east=D\nC north=D south=E\nD east=F\nE west=F\nF south=G\nG south=D"
+	run := func(seed int64) string {
+		w, err := world.NewFromReader(strings.NewReader(worldDef), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+		ao, err := NewOrchestrator(4, seed, w, DefaultRules(), log.New(&buf, "", 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ao.Run(StopConditions{MaxTurns: 100})
+		fmt.Fprint(&buf, w.String())
+
+		return buf.String()
+	}
+
+	t.Run("same seed leads to the same run, even concurrently", func(tt *testing.T) {
+		expected := run(42)
+
+		var wg sync.WaitGroup
+		results := make([]string, 8)
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i] = run(42)
+			}(i)
+		}
+		wg.Wait()
+
+		for _, result := range results {
+			assert.Equal(tt, expected, result)
+		}
+	})
+
+	t.Run("different seeds lead to different runs", func(tt *testing.T) {
+		assert.NotEqual(tt, run(1), run(2))
+	})
+}
+
+func TestStepAlien(t *testing.T) {
+	worldDef := "A north=B east=C\nB east=D\nC north=D south=E\nD east=F\nE west=F\nF south=G\nG south=D"
+	record := func(seed int64, step func(ao *AlienOrchestrator)) []Event {
+		w, err := world.NewFromReader(strings.NewReader(worldDef), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ao, err := NewOrchestrator(6, seed, w, DefaultRules(), nopLogger)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var events []Event
+		ao.Subscribe(SubscriberFunc(func(e Event) {
+			events = append(events, e)
+		}))
+		step(ao)
+
+		return events
+	}
+
+	t.Run("moving one alien at a time leads to the same events as running the simulation", func(tt *testing.T) {
+		for seed := int64(1); seed <= 20; seed++ {
+			stop := StopConditions{MaxTurns: 50}
+			expected := record(seed, func(ao *AlienOrchestrator) { ao.Run(stop) })
+			events := record(seed, func(ao *AlienOrchestrator) {
+				reason := ao.Stopped(stop)
+				for ; reason == ""; reason = ao.Stopped(stop) {
+					ao.StepAlien()
+				}
+				ao.End(reason)
+			})
+
+			assert.Equal(tt, expected, events, "seed %d", seed)
+		}
+	})
+
+	t.Run("every alien moves once per turn, even when aliens are deleted", func(tt *testing.T) {
+		w, err := world.NewFromReader(strings.NewReader(worldDef), false)
+		if !assert.NoError(tt, err) {
+			return
+		}
+		ao, err := NewOrchestrator(4, 0, w, DefaultRules(), nopLogger)
+		if !assert.NoError(tt, err) {
+			return
+		}
+
+		ao.StepAlien()
+		ao.StepAlien()
+
+		// Deleting aliens that already moved keeps the cursor on the same alien
+		next := ao.Aliens[2]
+		ao.deleteAliens(ao.Aliens[:2])
+		assert.Equal(tt, next, ao.Aliens[ao.cursor])
+
+		// Deleting the last alien starts a new turn after the next one moves
+		ao.deleteAliens(ao.Aliens[1:])
+		ao.StepAlien()
+		assert.Equal(tt, 0, ao.cursor)
+		assert.False(tt, ao.inTurn)
+		assert.Equal(tt, 1, ao.turn)
+	})
+}
+
+func TestAliensByCity(t *testing.T) {
+	ao, _ := newOrchestratorWithAliens(t, "A east=B\nB east=C", DefaultRules(), "A", "C", "A")
+
+	assert.Equal(t, map[string][]int{"A": {1, 3}, "C": {2}}, ao.AliensByCity())
+}
+
+func TestTravel(t *testing.T) {
+	// A road that takes three turns to travel at speed 1
+	worldDef := `[{"name": "A", "neighbors": [{"name": "B", "length": 2.5}]}]`
+	rules := DefaultRules()
+	rules.Speed = 1
+
+	simultaneous := rules
+	simultaneous.TurnModel = TurnSimultaneous
+
+	for _, r := range []Rules{rules, simultaneous} {
+		t.Run(fmt.Sprintf("aliens arrive once they traveled the road, %s", r.TurnModel), func(tt *testing.T) {
+			ao, _ := newOrchestratorWithAliens(tt, worldDef, r, "A")
+
+			var events []Event
+			ao.Subscribe(SubscriberFunc(func(e Event) {
+				events = append(events, e)
+			}))
+			a := ao.Aliens[0]
+
+			ao.StepRound()
+			assert.True(tt, a.Traveling())
+			assert.Equal(tt, "A", a.Position.Name)
+			assert.Equal(tt, "B", a.Destination.Name)
+			assert.InDelta(tt, 1.0/3, a.Progress(), 1e-6)
+			assert.Equal(tt, 0, ao.AliensIn("A"))
+			assert.Equal(tt, 0, ao.AliensIn("B"))
+
+			ao.StepRound()
+			assert.True(tt, a.Traveling())
+
+			ao.StepRound()
+			assert.False(tt, a.Traveling())
+			assert.Equal(tt, "B", a.Position.Name)
+			assert.Equal(tt, 1, ao.AliensIn("B"))
+			assert.Equal(tt, []Event{
+				AlienDeparted{Turn: 1, AlienID: 1, From: "A", To: "B", Turns: 3},
+				AlienIsolated{Turn: 1, AlienID: 1, City: "B"},
+				AlienMoved{Turn: 3, AlienID: 1, From: "A", To: "B"},
+			}, events)
+		})
+
+		t.Run(fmt.Sprintf("aliens arriving to a destroyed city are trapped in its ruins, %s", r.TurnModel), func(tt *testing.T) {
+			ao, w := newOrchestratorWithAliens(tt, worldDef, r, "A")
+
+			var events []Event
+			ao.Subscribe(SubscriberFunc(func(e Event) {
+				events = append(events, e)
+			}))
+
+			ao.StepRound()
+			w.DeleteCityAndRoads(w.Cities["B"])
+			ao.StepRound()
+			ao.StepRound()
+
+			assert.Equal(tt, 0, len(ao.Aliens))
+			assert.Equal(tt, []Event{
+				AlienDeparted{Turn: 1, AlienID: 1, From: "A", To: "B", Turns: 3},
+				AlienIsolated{Turn: 1, AlienID: 1, City: "B"},
+				AlienTrapped{Turn: 3, AlienID: 1, City: "B", InRuins: true},
+			}, events)
+		})
+	}
+
+	t.Run("aliens on a road don't stop the simulation for being immobile", func(tt *testing.T) {
+		ao, w := newOrchestratorWithAliens(tt, worldDef, rules, "A")
+
+		ao.StepRound()
+		w.DeleteCityAndRoads(w.Cities["A"])
+		assert.Equal(tt, StopReason(""), ao.Stopped(StopConditions{NoMobileAliens: true}))
+	})
+}
+
+func TestTravelTime(t *testing.T) {
+	w, err := world.NewFromBytes([]byte(`[{"name": "A", "neighbors": [{"name": "B", "length": 2.5}, "C"]}]`), false)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	a, b, c := w.Cities["A"], w.Cities["B"], w.Cities["C"]
+
+	tests := []struct {
+		name     string
+		speed    float64
+		to       *world.City
+		expected int
+	}{
+		{"every road takes one turn without speed", 0, b, 1},
+		{"turns are rounded up", 1, b, 3},
+		{"fast aliens take at least one turn", 10, b, 1},
+		{"roads without length take one turn", 1, c, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			rules := DefaultRules()
+			rules.Speed = test.speed
+			ao, err := NewOrchestrator(0, 0, w, rules, nopLogger)
+			if !assert.NoError(tt, err) {
+				return
+			}
+
+			assert.Equal(tt, test.expected, ao.TravelTime(a, test.to))
+		})
+	}
+}
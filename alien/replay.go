@@ -0,0 +1,232 @@
+package alien
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/santihernandezc/alien-invasion/world"
+)
+
+// Replay applies the events recorded in a previous simulation to a World,
+// instead of making the aliens move by themselves.
+type Replay struct {
+	Aliens []*Alien
+
+	world  *world.World
+	events []Event
+	// next is the index of the next event to apply
+	next int
+}
+
+// NewReplay returns a Replay of the events on the given World, which must be
+// loaded from the same map used in the recorded simulation.
+// The starting city of each alien is derived from the first event it's involved in.
+func NewReplay(w *world.World, events []Event) (*Replay, error) {
+	if w == nil {
+		return nil, fmt.Errorf("invalid World value: <nil>")
+	}
+
+	replay := Replay{
+		world:  w,
+		events: events,
+	}
+
+	// Find the first city where each alien was seen
+	placed := make(map[int]struct{})
+	place := func(id int, cityName string) error {
+		if _, ok := placed[id]; ok {
+			return nil
+		}
+
+		city, ok := w.Cities[cityName]
+		if !ok {
+			return fmt.Errorf("city %q for Alien %d not found in World", cityName, id)
+		}
+
+		placed[id] = struct{}{}
+		replay.Aliens = append(replay.Aliens, &Alien{ID: id, Position: city})
+		return nil
+	}
+
+	for _, e := range events {
+		var err error
+		switch e := e.(type) {
+		case AlienDeparted:
+			err = place(e.AlienID, e.From)
+		case AlienMoved:
+			err = place(e.AlienID, e.From)
+		case AliensMet:
+			for _, id := range e.RivalIDs {
+				if err = place(id, e.City); err != nil {
+					break
+				}
+			}
+		case AlienTrapped:
+			err = place(e.AlienID, e.City)
+		case AlienIsolated:
+			err = place(e.AlienID, e.City)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(replay.Aliens, func(i, j int) bool { return replay.Aliens[i].ID < replay.Aliens[j].ID })
+
+	return &replay, nil
+}
+
+// Done reports whether every event was already applied.
+func (r *Replay) Done() bool {
+	return r.next >= len(r.events)
+}
+
+// Step applies the next event along with the events caused by it, e.g. an alien
+// moving followed by the fight in its new city, and returns the applied events.
+func (r *Replay) Step() ([]Event, error) {
+	var applied []Event
+	for !r.Done() {
+		e := r.events[r.next]
+
+		// Stop before the next event that isn't a consequence of the applied ones
+		if len(applied) > 0 && !isConsequence(e) {
+			break
+		}
+
+		if err := r.apply(e); err != nil {
+			return applied, fmt.Errorf("error applying event %d: %w", r.next+1, err)
+		}
+		applied = append(applied, e)
+		r.next++
+	}
+
+	return applied, nil
+}
+
+// isConsequence reports whether an event is caused by the ones before it.
+func isConsequence(e Event) bool {
+	switch e := e.(type) {
+	case AliensMet, AliensCrossed, AliensKilled, CityDamaged, CityDestroyed, RoadDestroyed, RoadBlocked, AlienIsolated, SimulationEnded:
+		return true
+	case AlienTrapped:
+		return e.InRuins
+	}
+
+	return false
+}
+
+func (r *Replay) apply(e Event) error {
+	switch e := e.(type) {
+	case AlienDeparted:
+		alien, err := r.alien(e.AlienID)
+		if err != nil {
+			return err
+		}
+
+		city, ok := r.world.Cities[e.To]
+		if !ok {
+			return fmt.Errorf("city %q not found in World", e.To)
+		}
+		// Replays don't have the turns spent on the road, so aliens stay where the road starts
+		alien.Destination = city
+		alien.travel, alien.traveled = e.Turns, 0
+
+	case AlienMoved:
+		alien, err := r.alien(e.AlienID)
+		if err != nil {
+			return err
+		}
+
+		city, ok := r.world.Cities[e.To]
+		if !ok {
+			return fmt.Errorf("city %q not found in World", e.To)
+		}
+		alien.Position, alien.Destination = city, nil
+		alien.travel, alien.traveled = 0, 0
+
+	case AliensKilled:
+		r.removeAliens(e.AlienIDs...)
+
+	case CityDestroyed:
+		city, ok := r.world.Cities[e.City]
+		if !ok {
+			return fmt.Errorf("city %q not found in World", e.City)
+		}
+		r.world.DeleteCityAndRoads(city)
+		r.removeAliens(e.AlienIDs...)
+
+	case RoadDestroyed:
+		from, to, err := r.road(e.From, e.To)
+		if err != nil {
+			return err
+		}
+		_, err = r.world.DestroyRoad(from, to)
+		return err
+
+	case RoadBlocked:
+		from, to, err := r.road(e.From, e.To)
+		if err != nil {
+			return err
+		}
+		_, err = r.world.BlockRoad(from, to, e.Turns)
+		return err
+
+	case RoadReopened:
+		from, to, err := r.road(e.From, e.To)
+		if err != nil {
+			return err
+		}
+		_, err = r.world.UnblockRoad(from, to)
+		return err
+
+	case AlienTrapped:
+		r.removeAliens(e.AlienID)
+
+	case AlienIsolated:
+		if e.Retired {
+			r.removeAliens(e.AlienID)
+		}
+	}
+
+	return nil
+}
+
+// road returns the cities at both ends of a road.
+func (r *Replay) road(fromName, toName string) (from, to *world.City, err error) {
+	from, ok := r.world.Cities[fromName]
+	if !ok {
+		return nil, nil, fmt.Errorf("city %q not found in World", fromName)
+	}
+	to, ok = r.world.Cities[toName]
+	if !ok {
+		return nil, nil, fmt.Errorf("city %q not found in World", toName)
+	}
+
+	return from, to, nil
+}
+
+func (r *Replay) alien(id int) (*Alien, error) {
+	for _, a := range r.Aliens {
+		if a.ID == id {
+			return a, nil
+		}
+	}
+
+	return nil, fmt.Errorf("alien %d not found", id)
+}
+
+func (r *Replay) removeAliens(ids ...int) {
+	toRemove := make(map[int]struct{}, len(ids))
+	for _, id := range ids {
+		toRemove[id] = struct{}{}
+	}
+
+	remainingAliens := make([]*Alien, 0, len(r.Aliens))
+	for _, a := range r.Aliens {
+		if _, ok := toRemove[a.ID]; !ok {
+			remainingAliens = append(remainingAliens, a)
+		}
+	}
+	r.Aliens = remainingAliens
+}
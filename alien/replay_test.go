@@ -0,0 +1,136 @@
+package alien
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/santihernandezc/alien-invasion/world"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplay(t *testing.T) {
+	worldDef := "A north=B east=C\nB east=D\nC north=D south=E\nD east=F\nE west=F\nF south=G\nG south=D"
+
+	// Cities lose a road in every fight, which is blocked for a while
+	roadsBlocked := DefaultRules()
+	roadsBlocked.DestroyCity = false
+	roadsBlocked.RoadsLost = 1
+	roadsBlocked.BlockTurns = 2
+
+	// Aliens that can't meet any other alien leave the simulation
+	retireIsolated := roadsBlocked
+	retireIsolated.RetireIsolated = true
+
+	for _, rules := range []Rules{DefaultRules(), roadsBlocked, retireIsolated} {
+		for seed := int64(0); seed < 20; seed++ {
+			// Record a simulation
+			w, err := world.NewFromReader(strings.NewReader(worldDef), false)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			ao, err := NewOrchestrator(5, seed, w, rules, nopLogger)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			var events []Event
+			ao.Subscribe(SubscriberFunc(func(e Event) {
+				events = append(events, e)
+			}))
+			ao.Run(StopConditions{MaxTurns: 20})
+
+			// Replay it on a new World
+			replayWorld, err := world.NewFromReader(strings.NewReader(worldDef), false)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			replay, err := NewReplay(replayWorld, events)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			var replayed []Event
+			for !replay.Done() {
+				applied, err := replay.Step()
+				if !assert.NoError(t, err) {
+					return
+				}
+				assert.NotEmpty(t, applied)
+				replayed = append(replayed, applied...)
+			}
+
+			assert.Equal(t, events, replayed)
+			assert.Equal(t, w.String(), replayWorld.String())
+			assert.ElementsMatch(t, alienIDs(ao.Aliens), alienIDs(replay.Aliens))
+			for _, a := range replay.Aliens {
+				for _, original := range ao.Aliens {
+					if a.ID == original.ID {
+						assert.Equal(t, original.Position.Name, a.Position.Name)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestNewReplay(t *testing.T) {
+	w, err := world.NewFromReader(strings.NewReader("A north=B"), false)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	t.Run("nil world", func(tt *testing.T) {
+		_, err := NewReplay(nil, nil)
+		assert.EqualError(tt, err, "invalid World value: <nil>")
+	})
+
+	t.Run("unknown city", func(tt *testing.T) {
+		_, err := NewReplay(w, []Event{AlienMoved{Turn: 1, AlienID: 1, From: "C", To: "A"}})
+		assert.EqualError(tt, err, `city "C" for Alien 1 not found in World`)
+	})
+
+	t.Run("unknown destination", func(tt *testing.T) {
+		replay, err := NewReplay(w, []Event{AlienMoved{Turn: 1, AlienID: 1, From: "A", To: "C"}})
+		if !assert.NoError(tt, err) {
+			return
+		}
+
+		_, err = replay.Step()
+		assert.EqualError(tt, err, `error applying event 1: city "C" not found in World`)
+	})
+
+	t.Run("destroyed cities lose their blocked roads", func(tt *testing.T) {
+		w, err := world.NewFromReader(strings.NewReader("A north=B east=C"), false)
+		if !assert.NoError(tt, err) {
+			return
+		}
+		replay, err := NewReplay(w, []Event{
+			AlienMoved{Turn: 1, AlienID: 1, From: "A", To: "B"},
+			RoadBlocked{Turn: 1, From: "A", To: "C", Turns: 3},
+			CityDestroyed{Turn: 1, City: "C"},
+		})
+		if !assert.NoError(tt, err) {
+			return
+		}
+
+		_, err = replay.Step()
+		if assert.NoError(tt, err) {
+			assert.Empty(tt, w.BlockedRoads())
+		}
+	})
+
+	t.Run("departed aliens stay where the road starts", func(tt *testing.T) {
+		replay, err := NewReplay(w, []Event{AlienDeparted{Turn: 1, AlienID: 1, From: "A", To: "B", Turns: 3}})
+		if !assert.NoError(tt, err) {
+			return
+		}
+
+		_, err = replay.Step()
+		if assert.NoError(tt, err) {
+			assert.Equal(tt, w.Cities["B"], replay.Aliens[0].Destination)
+			assert.Equal(tt, float32(0), replay.Aliens[0].Progress())
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package alien
+
+import "fmt"
+
+// Turn models, which define the order in which aliens move.
+const (
+	// TurnSequential moves one alien at a time, each of them fighting as soon as it arrives to a city.
+	TurnSequential = "sequential"
+	// TurnSimultaneous makes every alien choose where to go before any of them moves,
+	// and resolves the fights once they all arrived.
+	TurnSimultaneous = "simultaneous"
+)
+
+// Rules define how aliens take turns and what happens when they meet.
+type Rules struct {
+	// TurnModel is either TurnSequential or TurnSimultaneous, aliens move sequentially if it's empty.
+	TurnModel string `json:"turn_model"`
+	// CrossingFights tells whether aliens crossing each other on the same road fight.
+	// Aliens can only cross each other in the simultaneous turn model.
+	CrossingFights bool `json:"crossing_fights"`
+	// Threshold is the amount of aliens in a city, including the one arriving, that starts a fight.
+	// Aliens below the threshold share the city peacefully.
+	Threshold int `json:"threshold"`
+	// ArrivingSurvives tells whether the alien that arrives to the city and starts the fight survives it.
+	ArrivingSurvives bool `json:"arriving_survives"`
+	// DestroyCity tells whether fights destroy the city. If they do, the aliens surviving the fight
+	// are trapped in the ruins, otherwise they stay in the city and keep moving.
+	DestroyCity bool `json:"destroy_city"`
+	// SurvivalProbability is the probability of each alien surviving a fight.
+	// Fights have a deterministic outcome if it's 0, every alien in them dies.
+	SurvivalProbability float64 `json:"survival_probability"`
+	// Speed is the length of road aliens travel per turn. Roads take as many turns as needed to travel
+	// their length, and aliens on a road can't meet the ones in cities. Every road takes a single turn if it's 0.
+	Speed float64 `json:"speed,omitempty"`
+	// RoadsLost is the amount of roads, picked at random, a city loses in every fight it withstands,
+	// which are the fights that don't destroy it. Aliens crossing each other also break their road.
+	RoadsLost int `json:"roads_lost,omitempty"`
+	// BlockTurns is the amount of turns the roads lost stay blocked. They're destroyed for good if it's 0.
+	BlockTurns int `json:"block_turns,omitempty"`
+	// RetireIsolated tells whether the aliens that can't meet any other alien anymore are retired,
+	// which takes them out of the simulation like the trapped ones, otherwise they keep moving.
+	RetireIsolated bool `json:"retire_isolated,omitempty"`
+}
+
+// DefaultRules returns the classic rules: when two aliens meet, they kill each other and destroy the city.
+func DefaultRules() Rules {
+	return Rules{
+		TurnModel:   TurnSequential,
+		Threshold:   2,
+		DestroyCity: true,
+	}
+}
+
+func (r Rules) validate() error {
+	switch r.TurnModel {
+	case "", TurnSequential, TurnSimultaneous:
+	default:
+		return fmt.Errorf("invalid rules: unknown turn model %q", r.TurnModel)
+	}
+	if r.Threshold < 2 {
+		return fmt.Errorf("invalid rules: threshold must be at least 2, got %d", r.Threshold)
+	}
+	if r.SurvivalProbability < 0 || r.SurvivalProbability > 1 {
+		return fmt.Errorf("invalid rules: survival probability must be between 0 and 1, got %g", r.SurvivalProbability)
+	}
+	if r.Speed < 0 {
+		return fmt.Errorf("invalid rules: speed can't be negative, got %g", r.Speed)
+	}
+	if r.RoadsLost < 0 {
+		return fmt.Errorf("invalid rules: roads lost can't be negative, got %d", r.RoadsLost)
+	}
+	if r.BlockTurns < 0 {
+		return fmt.Errorf("invalid rules: block turns can't be negative, got %d", r.BlockTurns)
+	}
+
+	return nil
+}
@@ -0,0 +1,393 @@
+package alien
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/santihernandezc/alien-invasion/world"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRulesValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules Rules
+		err   string
+	}{
+		{
+			"default rules",
+			DefaultRules(),
+			"",
+		},
+		{
+			"unknown turn model",
+			Rules{TurnModel: "random", Threshold: 2},
+			`invalid rules: unknown turn model "random"`,
+		},
+		{
+			"threshold too low",
+			Rules{Threshold: 1},
+			"invalid rules: threshold must be at least 2, got 1",
+		},
+		{
+			"negative survival probability",
+			Rules{Threshold: 2, SurvivalProbability: -0.5},
+			"invalid rules: survival probability must be between 0 and 1, got -0.5",
+		},
+		{
+			"survival probability above 1",
+			Rules{Threshold: 2, SurvivalProbability: 1.5},
+			"invalid rules: survival probability must be between 0 and 1, got 1.5",
+		},
+		{
+			"negative speed",
+			Rules{Threshold: 2, Speed: -1},
+			"invalid rules: speed can't be negative, got -1",
+		},
+		{
+			"negative roads lost",
+			Rules{Threshold: 2, RoadsLost: -1},
+			"invalid rules: roads lost can't be negative, got -1",
+		},
+		{
+			"negative block turns",
+			Rules{Threshold: 2, BlockTurns: -2},
+			"invalid rules: block turns can't be negative, got -2",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			err := test.rules.validate()
+			if test.err != "" {
+				assert.EqualError(tt, err, test.err)
+				return
+			}
+			assert.NoError(tt, err)
+		})
+	}
+}
+
+func TestFight(t *testing.T) {
+	tests := []struct {
+		name string
+		// Aliens start in City2, alien 1 arrives from City1
+		rivals             int
+		rules              Rules
+		expectedAliens     []int
+		expectedDestroyed  bool
+		expectedKilled     []int
+		expectedInLastCity int
+	}{
+		{
+			"default rules",
+			1,
+			DefaultRules(),
+			[]int{},
+			true,
+			[]int{1, 2},
+			0,
+		},
+		{
+			"below the threshold",
+			1,
+			Rules{Threshold: 3, DestroyCity: true},
+			[]int{1, 2},
+			false,
+			nil,
+			2,
+		},
+		{
+			"reaching the threshold",
+			2,
+			Rules{Threshold: 3, DestroyCity: true},
+			[]int{},
+			true,
+			[]int{1, 2, 3},
+			0,
+		},
+		{
+			"arriving alien survives, trapped in the ruins",
+			1,
+			Rules{Threshold: 2, ArrivingSurvives: true, DestroyCity: true},
+			[]int{},
+			true,
+			[]int{2},
+			0,
+		},
+		{
+			"arriving alien survives, city kept",
+			1,
+			Rules{Threshold: 2, ArrivingSurvives: true},
+			[]int{1},
+			false,
+			[]int{2},
+			1,
+		},
+		{
+			"everyone survives",
+			1,
+			Rules{Threshold: 2, SurvivalProbability: 1},
+			[]int{1, 2},
+			false,
+			nil,
+			2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			w, err := world.NewFromReader(strings.NewReader("City1 south=City2\nCity2 north=City1"), false)
+			if !assert.NoError(tt, err) {
+				return
+			}
+
+			ao, err := NewOrchestrator(0, 0, w, test.rules, nopLogger)
+			if !assert.NoError(tt, err) {
+				return
+			}
+
+			// Place the aliens by hand so alien 1 is the only one moving
+			arriving := &Alien{ID: 1, Position: w.Cities["City1"]}
+			ao.Aliens = append(ao.Aliens, arriving)
+			ao.addAlienToCity("City1", arriving)
+			for i := 2; i <= test.rivals+1; i++ {
+				rival := &Alien{ID: i, Position: w.Cities["City2"]}
+				ao.Aliens = append(ao.Aliens, rival)
+				ao.addAlienToCity("City2", rival)
+			}
+
+			var killed []int
+			ao.Subscribe(SubscriberFunc(func(e Event) {
+				if e, ok := e.(AliensKilled); ok {
+					killed = append(killed, e.AlienIDs...)
+				}
+			}))
+
+			ao.StepAlien()
+
+			assert.Equal(tt, test.expectedAliens, alienIDs(ao.Aliens))
+			assert.Equal(tt, test.expectedKilled, killed)
+			_, ok := w.Cities["City2"]
+			assert.Equal(tt, test.expectedDestroyed, !ok)
+			assert.Equal(tt, test.expectedInLastCity, ao.AliensIn("City2"))
+		})
+	}
+}
+
+func TestCityAttributes(t *testing.T) {
+	// Alien 1 arrives to B from A, where the rest of the aliens are
+	worldDef := func(attributes string) string {
+		return `[{"name": "A", "neighbors": ["B"]}, {"name": "B", ` + attributes + `"neighbors": []}]`
+	}
+
+	tests := []struct {
+		name      string
+		world     string
+		rules     Rules
+		expected  []Event
+		destroyed bool
+		survivors int
+	}{
+		{
+			"city with hit points left is damaged",
+			worldDef(`"population": 1000, "hit_points": 3, `),
+			DefaultRules(),
+			[]Event{
+				AlienMoved{Turn: 1, AlienID: 1, From: "A", To: "B"},
+				AliensMet{Turn: 1, AlienID: 1, RivalIDs: []int{2}, City: "B"},
+				AliensKilled{Turn: 1, City: "B", AlienIDs: []int{1, 2}},
+				CityDamaged{Turn: 1, City: "B", AlienIDs: []int{1, 2}, HitPoints: 2, PopulationLost: 333},
+			},
+			false,
+			0,
+		},
+		{
+			"city with a single hit point is destroyed",
+			worldDef(`"population": 1000, "hit_points": 1, `),
+			DefaultRules(),
+			[]Event{
+				AlienMoved{Turn: 1, AlienID: 1, From: "A", To: "B"},
+				AliensMet{Turn: 1, AlienID: 1, RivalIDs: []int{2}, City: "B"},
+				AliensKilled{Turn: 1, City: "B", AlienIDs: []int{1, 2}},
+				CityDestroyed{Turn: 1, City: "B", AlienIDs: []int{1, 2}},
+			},
+			true,
+			0,
+		},
+		{
+			"city repelling the fight",
+			worldDef(`"defense": 1, `),
+			DefaultRules(),
+			[]Event{
+				AlienMoved{Turn: 1, AlienID: 1, From: "A", To: "B"},
+				AliensMet{Turn: 1, AlienID: 1, RivalIDs: []int{2}, City: "B"},
+				AliensKilled{Turn: 1, City: "B", AlienIDs: []int{1, 2}},
+				CityDamaged{Turn: 1, City: "B", AlienIDs: []int{1, 2}, HitPoints: 1, Repelled: true},
+			},
+			false,
+			0,
+		},
+		{
+			"survivors stay in a damaged city",
+			worldDef(`"hit_points": 2, `),
+			Rules{Threshold: 2, ArrivingSurvives: true, DestroyCity: true},
+			[]Event{
+				AlienMoved{Turn: 1, AlienID: 1, From: "A", To: "B"},
+				AliensMet{Turn: 1, AlienID: 1, RivalIDs: []int{2}, City: "B"},
+				AliensKilled{Turn: 1, City: "B", AlienIDs: []int{2}},
+				CityDamaged{Turn: 1, City: "B", AlienIDs: []int{1, 2}, HitPoints: 1},
+				AlienIsolated{Turn: 1, AlienID: 1, City: "B"},
+			},
+			false,
+			1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			ao, w := newOrchestratorWithAliens(tt, test.world, test.rules, "A", "B")
+
+			var events []Event
+			ao.Subscribe(SubscriberFunc(func(e Event) {
+				events = append(events, e)
+			}))
+			ao.StepAlien()
+
+			assert.Equal(tt, test.expected, events)
+			_, ok := w.Cities["B"]
+			assert.Equal(tt, test.destroyed, !ok)
+			assert.Equal(tt, test.survivors, ao.AliensIn("B"))
+		})
+	}
+
+	t.Run("cities are destroyed once they run out of hit points", func(tt *testing.T) {
+		ao, w := newOrchestratorWithAliens(tt, worldDef(`"population": 1000, "hit_points": 2, `), DefaultRules(), "A", "B")
+		ao.StepAlien()
+
+		for _, a := range []*Alien{{ID: 3, Position: w.Cities["A"]}, {ID: 4, Position: w.Cities["B"]}} {
+			ao.Aliens = append(ao.Aliens, a)
+			ao.addAlienToCity(a.Position.Name, a)
+		}
+		ao.StepAlien()
+
+		assert.Equal(tt, 1, len(w.DestroyedCities))
+		assert.Equal(tt, 1000, ao.Losses().Population)
+	})
+}
+
+func TestRoadsLost(t *testing.T) {
+	keepCities := Rules{Threshold: 2, RoadsLost: 5}
+	blocking := Rules{Threshold: 2, ArrivingSurvives: true, RoadsLost: 5, BlockTurns: 2}
+	damaging := DefaultRules()
+	damaging.RoadsLost = 5
+	crossing := Rules{TurnModel: TurnSimultaneous, CrossingFights: true, Threshold: 2, RoadsLost: 1}
+
+	tests := []struct {
+		name     string
+		world    string
+		rules    Rules
+		cities   []string
+		rounds   int
+		expected []Event
+		roads    string
+	}{
+		{
+			"kept city loses its roads",
+			"A east=B\nB east=C",
+			keepCities,
+			[]string{"A", "B"},
+			1,
+			[]Event{
+				AlienMoved{Turn: 1, AlienID: 1, From: "A", To: "B"},
+				AliensMet{Turn: 1, AlienID: 1, RivalIDs: []int{2}, City: "B"},
+				AliensKilled{Turn: 1, City: "B", AlienIDs: []int{1, 2}},
+				RoadDestroyed{Turn: 1, From: "B", To: "A"},
+				RoadDestroyed{Turn: 1, From: "B", To: "C"},
+			},
+			"A\nB\nC\n",
+		},
+		{
+			"damaged city loses its roads",
+			`[{"name": "A", "neighbors": ["B"]}, {"name": "B", "hit_points": 2, "neighbors": ["C"]}]`,
+			damaging,
+			[]string{"A", "B"},
+			1,
+			[]Event{
+				AlienMoved{Turn: 1, AlienID: 1, From: "A", To: "B"},
+				AliensMet{Turn: 1, AlienID: 1, RivalIDs: []int{2}, City: "B"},
+				AliensKilled{Turn: 1, City: "B", AlienIDs: []int{1, 2}},
+				CityDamaged{Turn: 1, City: "B", AlienIDs: []int{1, 2}, HitPoints: 1},
+				RoadDestroyed{Turn: 1, From: "B", To: "A"},
+				RoadDestroyed{Turn: 1, From: "B", To: "C"},
+			},
+			"A\nB\nC\n",
+		},
+		{
+			"city repelling the fight keeps its roads",
+			`[{"name": "A", "neighbors": [{"name": "B", "direction": "east"}]}, {"name": "B", "defense": 1, "neighbors": []}]`,
+			damaging,
+			[]string{"A", "B"},
+			1,
+			[]Event{
+				AlienMoved{Turn: 1, AlienID: 1, From: "A", To: "B"},
+				AliensMet{Turn: 1, AlienID: 1, RivalIDs: []int{2}, City: "B"},
+				AliensKilled{Turn: 1, City: "B", AlienIDs: []int{1, 2}},
+				CityDamaged{Turn: 1, City: "B", AlienIDs: []int{1, 2}, HitPoints: 1, Repelled: true},
+			},
+			"A east=B\nB west=A\n",
+		},
+		{
+			"aliens wait for blocked roads to open",
+			"A east=B\nB east=C",
+			blocking,
+			[]string{"A", "B"},
+			3,
+			[]Event{
+				AlienMoved{Turn: 1, AlienID: 1, From: "A", To: "B"},
+				AliensMet{Turn: 1, AlienID: 1, RivalIDs: []int{2}, City: "B"},
+				AliensKilled{Turn: 1, City: "B", AlienIDs: []int{2}},
+				RoadBlocked{Turn: 1, From: "B", To: "A", Turns: 2},
+				RoadBlocked{Turn: 1, From: "B", To: "C", Turns: 2},
+				AlienIsolated{Turn: 1, AlienID: 1, City: "B"},
+				RoadReopened{Turn: 3, From: "B", To: "A"},
+				RoadReopened{Turn: 3, From: "B", To: "C"},
+				AlienMoved{Turn: 3, AlienID: 1, From: "B", To: "A"},
+			},
+			"A east=B\nB west=A east=C\nC west=B\n",
+		},
+		{
+			"aliens crossing each other break the road",
+			"A east=B\nB east=C",
+			crossing,
+			[]string{"A", "B"},
+			1,
+			[]Event{
+				AlienMoved{Turn: 1, AlienID: 1, From: "A", To: "B"},
+				AlienMoved{Turn: 1, AlienID: 2, From: "B", To: "A"},
+				AliensCrossed{Turn: 1, AlienIDs: []int{1}, RivalIDs: []int{2}, From: "A", To: "B"},
+				AliensKilled{Turn: 1, City: "B", AlienIDs: []int{1}, OnRoad: true},
+				AliensKilled{Turn: 1, City: "A", AlienIDs: []int{2}, OnRoad: true},
+				RoadDestroyed{Turn: 1, From: "A", To: "B"},
+			},
+			"A\nB east=C\nC west=B\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			ao, w := newOrchestratorWithAliens(tt, test.world, test.rules, test.cities...)
+
+			var events []Event
+			ao.Subscribe(SubscriberFunc(func(e Event) {
+				events = append(events, e)
+			}))
+			for i := 0; i < test.rounds; i++ {
+				ao.StepRound()
+			}
+
+			assert.Equal(tt, test.expected, events)
+			assert.Equal(tt, test.roads, w.String())
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package alien
+
+import "github.com/santihernandezc/alien-invasion/world"
+
+// road is a road between two cities, traveled in the direction from -> to.
+type road struct {
+	from *world.City
+	to   *world.City
+}
+
+// moveSimultaneously makes every alien choose where to go before any of them moves,
+// so the outcome doesn't depend on the order of the aliens. Once they all moved, the
+// aliens that took the same road in opposite directions fight, if the rules say so,
+// and then the ones arriving to a city fight the aliens in it.
+func (ao *AlienOrchestrator) moveSimultaneously() {
+	// Choose the destinations while the aliens can still see each other in their cities.
+	// Aliens already on a road keep going, and the ones waiting for a road to open stay.
+	var departed []*Alien
+	origins := make(map[*Alien]*world.City, len(ao.Aliens))
+	for _, alien := range ao.Aliens {
+		ao.touchAlien(alien)
+		if alien.Traveling() || ao.waiting(alien) {
+			continue
+		}
+
+		from := alien.Position
+		if ok := alien.move(ao, ao.rng); !ok {
+			ao.emit(AlienTrapped{Turn: ao.turn, AlienID: alien.ID, City: alien.Position.Name})
+			ao.deleteAliens([]*Alien{alien})
+			continue
+		}
+
+		departed = append(departed, alien)
+		origins[alien] = from
+	}
+
+	var moved []*Alien
+	for _, alien := range ao.Aliens {
+		from, ok := origins[alien]
+		if ok {
+			ao.removeAlienFromCity(from.Name, alien)
+			if ao.depart(alien, from.Name) {
+				continue
+			}
+		} else if alien.Traveling() {
+			from = alien.Position
+			if !alien.advance() {
+				continue
+			}
+		} else {
+			continue
+		}
+
+		if ao.arrivedInRuins(alien) {
+			continue
+		}
+		to := alien.Position.Name
+		ao.addAlienToCity(to, alien)
+		ao.emit(AlienMoved{Turn: ao.turn, AlienID: alien.ID, From: from.Name, To: to})
+		moved = append(moved, alien)
+	}
+
+	if ao.rules.CrossingFights {
+		ao.fightOnRoads(departed, origins)
+	}
+
+	// Group the aliens that survived the trip by the city they arrived to
+	var cities []*world.City
+	arrivals := make(map[*world.City][]*Alien)
+	for _, alien := range moved {
+		if alien.isDeleted {
+			continue
+		}
+		if _, ok := arrivals[alien.Position]; !ok {
+			cities = append(cities, alien.Position)
+		}
+		arrivals[alien.Position] = append(arrivals[alien.Position], alien)
+	}
+
+	for _, city := range cities {
+		aliens := ao.positions[city.Name]
+		if len(aliens) < 2 {
+			continue
+		}
+
+		arriving := arrivals[city]
+		rivals := make([]*Alien, 0, len(aliens)-1)
+		for _, a := range aliens {
+			if a != arriving[0] {
+				rivals = append(rivals, a)
+			}
+		}
+		ao.emit(AliensMet{Turn: ao.turn, AlienID: arriving[0].ID, RivalIDs: alienIDs(rivals), City: city.Name})
+
+		// Aliens fight if there are enough of them in the city
+		if len(aliens) >= ao.rules.Threshold {
+			ao.fight(arriving, city)
+		}
+	}
+}
+
+// fightOnRoads makes the aliens that took the same road in opposite directions fight.
+// Survivors carry on to their destination, and the road breaks if the rules say cities lose roads.
+func (ao *AlienOrchestrator) fightOnRoads(departed []*Alien, origins map[*Alien]*world.City) {
+	var roads []road
+	travelers := make(map[road][]*Alien)
+	for _, alien := range departed {
+		to := alien.Position
+		if alien.Traveling() {
+			to = alien.Destination
+		}
+
+		r := road{from: origins[alien], to: to}
+		if _, ok := travelers[r]; !ok {
+			roads = append(roads, r)
+		}
+		travelers[r] = append(travelers[r], alien)
+	}
+
+	for _, r := range roads {
+		opposite := road{from: r.to, to: r.from}
+		aliens, rivals := travelers[r], travelers[opposite]
+		if len(aliens) == 0 || len(rivals) == 0 {
+			continue
+		}
+
+		// Each pair of roads is resolved only once
+		delete(travelers, r)
+		delete(travelers, opposite)
+
+		ao.emit(AliensCrossed{Turn: ao.turn, AlienIDs: alienIDs(aliens), RivalIDs: alienIDs(rivals), From: r.from.Name, To: r.to.Name})
+		ao.killOnRoad(aliens, r.to)
+		ao.killOnRoad(rivals, r.from)
+		if ao.rules.RoadsLost > 0 {
+			ao.breakRoad(r.from, r.to)
+			ao.breakRoad(r.to, r.from)
+		}
+	}
+}
+
+// killOnRoad resolves the fight for the aliens on their way to a city.
+func (ao *AlienOrchestrator) killOnRoad(aliens []*Alien, to *world.City) {
+	var dead []*Alien
+	for _, a := range aliens {
+		if !ao.survives(false) {
+			dead = append(dead, a)
+			ao.removeAlienFromCity(to.Name, a)
+		}
+	}
+
+	if len(dead) > 0 {
+		ao.emit(AliensKilled{Turn: ao.turn, City: to.Name, AlienIDs: alienIDs(dead), OnRoad: true})
+		ao.deleteAliens(dead)
+	}
+}
@@ -0,0 +1,104 @@
+package alien
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/santihernandezc/alien-invasion/world"
+	"github.com/stretchr/testify/assert"
+)
+
+// newOrchestratorWithAliens returns an orchestrator for the given text or JSON map,
+// with an alien in each of the given cities, with IDs starting at 1.
+func newOrchestratorWithAliens(t *testing.T, worldDef string, rules Rules, cities ...string) (*AlienOrchestrator, *world.World) {
+	var w *world.World
+	var err error
+	if strings.HasPrefix(worldDef, "[") {
+		w, err = world.NewFromBytes([]byte(worldDef), false)
+	} else {
+		w, err = world.NewFromReader(strings.NewReader(worldDef), false)
+	}
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	ao, err := NewOrchestrator(0, 0, w, rules, nopLogger)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	for i, city := range cities {
+		a := &Alien{ID: i + 1, Position: w.Cities[city]}
+		ao.Aliens = append(ao.Aliens, a)
+		ao.addAlienToCity(city, a)
+	}
+
+	return ao, w
+}
+
+func TestMoveSimultaneously(t *testing.T) {
+	simultaneous := DefaultRules()
+	simultaneous.TurnModel = TurnSimultaneous
+
+	crossing := simultaneous
+	crossing.CrossingFights = true
+
+	t.Run("aliens swapping cities don't meet", func(tt *testing.T) {
+		ao, w := newOrchestratorWithAliens(tt, "A east=B", simultaneous, "A", "B")
+		ao.StepRound()
+
+		assert.Equal(tt, []int{1, 2}, alienIDs(ao.Aliens))
+		assert.Equal(tt, "B", ao.Aliens[0].Position.Name)
+		assert.Equal(tt, "A", ao.Aliens[1].Position.Name)
+		assert.Equal(tt, 2, len(w.Cities))
+	})
+
+	t.Run("aliens swapping cities fight on the road", func(tt *testing.T) {
+		ao, w := newOrchestratorWithAliens(tt, "A east=B", crossing, "A", "B")
+
+		var events []Event
+		ao.Subscribe(SubscriberFunc(func(e Event) {
+			events = append(events, e)
+		}))
+		ao.StepRound()
+
+		assert.Equal(tt, 0, len(ao.Aliens))
+		assert.Equal(tt, 0, ao.AliensIn("A"))
+		assert.Equal(tt, 0, ao.AliensIn("B"))
+		assert.Equal(tt, 2, len(w.Cities))
+		assert.Equal(tt, []Event{
+			AlienMoved{Turn: 1, AlienID: 1, From: "A", To: "B"},
+			AlienMoved{Turn: 1, AlienID: 2, From: "B", To: "A"},
+			AliensCrossed{Turn: 1, AlienIDs: []int{1}, RivalIDs: []int{2}, From: "A", To: "B"},
+			AliensKilled{Turn: 1, City: "B", AlienIDs: []int{1}, OnRoad: true},
+			AliensKilled{Turn: 1, City: "A", AlienIDs: []int{2}, OnRoad: true},
+		}, events)
+	})
+
+	t.Run("every alien arriving to a city fights", func(tt *testing.T) {
+		ao, w := newOrchestratorWithAliens(tt, "X west=L1 east=L2 north=L3", simultaneous, "L1", "L2", "L3")
+
+		var killed []int
+		ao.Subscribe(SubscriberFunc(func(e Event) {
+			if e, ok := e.(AliensKilled); ok {
+				killed = append(killed, e.AlienIDs...)
+			}
+		}))
+		ao.StepRound()
+
+		assert.Equal(tt, 0, len(ao.Aliens))
+		assert.Equal(tt, []int{1, 2, 3}, killed)
+		_, ok := w.Cities["X"]
+		assert.False(tt, ok)
+	})
+
+	t.Run("outcome doesn't depend on the order of the aliens", func(tt *testing.T) {
+		ao, w := newOrchestratorWithAliens(tt, "X west=L1 east=L2 north=L3", simultaneous, "L1", "L2", "L3")
+		ao.Aliens[0], ao.Aliens[2] = ao.Aliens[2], ao.Aliens[0]
+		ao.StepRound()
+
+		assert.Equal(tt, 0, len(ao.Aliens))
+		_, ok := w.Cities["X"]
+		assert.False(tt, ok)
+	})
+}
@@ -0,0 +1,286 @@
+package alien
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+
+	"github.com/santihernandezc/alien-invasion/world"
+)
+
+// SnapshotVersion is the version of the format written by Snapshot.
+// It changes whenever snapshots written by older versions can't be restored anymore.
+const SnapshotVersion = 1
+
+// snapshot is the saved state of a simulation.
+type snapshot struct {
+	Version int   `json:"version"`
+	Turn    int   `json:"turn"`
+	Cursor  int   `json:"cursor"`
+	InTurn  bool  `json:"in_turn"`
+	Rules   Rules `json:"rules"`
+	// Seed and Draws are the state of the random source, which is restored by
+	// drawing the same amount of values from a source with the same seed
+	Seed   int64           `json:"seed"`
+	Draws  uint64          `json:"draws"`
+	Aliens []alienSnapshot `json:"aliens"`
+	// Positions has the aliens in each city, in the order they arrived
+	Positions map[string][]int `json:"positions"`
+	// Damage has the hit points lost by each city that withstood a fight
+	Damage map[string]int `json:"damage,omitempty"`
+	World  world.Snapshot `json:"world"`
+}
+
+// alienSnapshot is the saved state of an alien and its strategy.
+type alienSnapshot struct {
+	ID       int    `json:"id"`
+	City     string `json:"city"`
+	Strategy string `json:"strategy,omitempty"`
+	// Recent are the cities remembered by the avoid-recent strategy
+	Recent []string `json:"recent,omitempty"`
+	// Destination, Travel and Traveled are set while the alien is on a road
+	Destination string `json:"destination,omitempty"`
+	Travel      int    `json:"travel,omitempty"`
+	Traveled    int    `json:"traveled,omitempty"`
+	// Isolated tells whether the alien can't meet any other alien anymore
+	Isolated bool `json:"isolated,omitempty"`
+}
+
+// Snapshot writes the complete state of the simulation to out as JSON, so that
+// Restore can resume it later. Subscribers, the history and the time limit are not saved.
+// Only the built-in strategies can be saved.
+func (ao *AlienOrchestrator) Snapshot(out io.Writer) error {
+	s := snapshot{
+		Version:   SnapshotVersion,
+		Turn:      ao.turn,
+		Cursor:    ao.cursor,
+		InTurn:    ao.inTurn,
+		Rules:     ao.rules,
+		Seed:      ao.source.seed,
+		Draws:     ao.draws(),
+		Aliens:    make([]alienSnapshot, 0, len(ao.Aliens)),
+		Positions: make(map[string][]int, len(ao.positions)),
+		Damage:    ao.damage,
+		World:     ao.world.Snapshot(),
+	}
+
+	for _, a := range ao.Aliens {
+		as := alienSnapshot{ID: a.ID, City: a.Position.Name, Isolated: a.isolated}
+		if a.Traveling() {
+			as.Destination = a.Destination.Name
+			as.Travel, as.Traveled = a.travel, a.traveled
+		}
+		switch strategy := a.Strategy.(type) {
+		case nil:
+		case RandomWalk:
+			as.Strategy = StrategyRandomWalk
+		case *AvoidRecent:
+			as.Strategy = StrategyAvoidRecent
+			for _, c := range strategy.recent {
+				as.Recent = append(as.Recent, c.Name)
+			}
+		case SeekNearest:
+			as.Strategy = StrategySeekNearest
+		case Flee:
+			as.Strategy = StrategyFlee
+		case PreferDegree:
+			as.Strategy = StrategyPreferDegree
+		default:
+			return fmt.Errorf("invalid strategy of alien %d: %T can't be saved", a.ID, a.Strategy)
+		}
+		s.Aliens = append(s.Aliens, as)
+	}
+
+	for city, aliens := range ao.positions {
+		if len(aliens) > 0 {
+			s.Positions[city] = alienIDs(aliens)
+		}
+	}
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// Restore reads a snapshot written by Snapshot and returns an orchestrator that continues
+// the simulation exactly where it was saved. Events are logged by default, like in NewOrchestrator.
+// Restoring the random source takes longer the more values were drawn before saving it.
+func Restore(r io.Reader, log *log.Logger) (*AlienOrchestrator, error) {
+	if log == nil {
+		return nil, fmt.Errorf("invalid value for logger: <nil>")
+	}
+
+	var s snapshot
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return nil, fmt.Errorf("error decoding snapshot: %w", err)
+	}
+	if s.Version != SnapshotVersion {
+		return nil, fmt.Errorf("invalid snapshot: unsupported version %d, expected %d", s.Version, SnapshotVersion)
+	}
+	if err := s.Rules.validate(); err != nil {
+		return nil, err
+	}
+	if s.Cursor < 0 || (s.Cursor > 0 && !s.InTurn) {
+		return nil, fmt.Errorf("invalid snapshot: cursor %d out of turn", s.Cursor)
+	}
+	// A turn in progress always has an alien left to move, the next turn starts otherwise
+	if s.InTurn && s.Cursor >= len(s.Aliens) {
+		return nil, fmt.Errorf("invalid snapshot: cursor %d past the %d aliens", s.Cursor, len(s.Aliens))
+	}
+
+	w, err := world.FromSnapshot(s.World)
+	if err != nil {
+		return nil, err
+	}
+
+	source := newCountingSource(s.Seed, s.Draws)
+	ao := AlienOrchestrator{
+		Aliens:    make([]*Alien, 0, len(s.Aliens)),
+		positions: make(map[string][]*Alien, len(s.Positions)),
+		damage:    make(map[string]int, len(s.Damage)),
+		world:     w,
+		log:       log,
+		rules:     s.Rules,
+		turn:      s.Turn,
+		cursor:    s.Cursor,
+		inTurn:    s.InTurn,
+		source:    source,
+		rng:       rand.New(source),
+		// The check isn't saved, so check again
+		checkIsolated: true,
+	}
+	ao.Subscribe(NewLogSubscriber(log))
+
+	aliens := make(map[int]*Alien, len(s.Aliens))
+	for _, as := range s.Aliens {
+		if _, ok := aliens[as.ID]; ok {
+			return nil, fmt.Errorf("invalid snapshot: duplicated alien %d", as.ID)
+		}
+
+		a := &Alien{ID: as.ID, isolated: as.Isolated}
+		if as.Destination == "" {
+			city, ok := w.Cities[as.City]
+			if !ok {
+				return nil, fmt.Errorf("invalid snapshot: alien %d is in unknown city %q", as.ID, as.City)
+			}
+			a.Position = city
+		} else {
+			// Both ends of the road might have been destroyed while the alien travels
+			if a.Position = findCity(w, as.City); a.Position == nil {
+				return nil, fmt.Errorf("invalid snapshot: alien %d is in unknown city %q", as.ID, as.City)
+			}
+			if a.Destination = findCity(w, as.Destination); a.Destination == nil {
+				return nil, fmt.Errorf("invalid snapshot: alien %d is going to unknown city %q", as.ID, as.Destination)
+			}
+			if as.Travel < 2 || as.Traveled < 1 || as.Traveled >= as.Travel {
+				return nil, fmt.Errorf("invalid snapshot: alien %d traveled %d of %d turns", as.ID, as.Traveled, as.Travel)
+			}
+			a.travel, a.traveled = as.Travel, as.Traveled
+		}
+		if as.Strategy != "" {
+			if a.Strategy, err = NewStrategy(as.Strategy); err != nil {
+				return nil, err
+			}
+		}
+		if avoid, ok := a.Strategy.(*AvoidRecent); ok {
+			for _, name := range as.Recent {
+				// Recent cities might have been destroyed since they were visited
+				c := findCity(w, name)
+				if c == nil {
+					return nil, fmt.Errorf("invalid snapshot: alien %d visited unknown city %q", as.ID, name)
+				}
+				avoid.recent = append(avoid.recent, c)
+			}
+		}
+
+		aliens[a.ID] = a
+		ao.Aliens = append(ao.Aliens, a)
+	}
+
+	for city, ids := range s.Positions {
+		c, ok := w.Cities[city]
+		if !ok {
+			return nil, fmt.Errorf("invalid snapshot: aliens in unknown city %q", city)
+		}
+
+		for _, id := range ids {
+			// Aliens trapped in a city without roads are deleted but stay in its positions
+			a, ok := aliens[id]
+			if !ok {
+				a = &Alien{ID: id, Position: c, isDeleted: true}
+				aliens[id] = a
+			}
+			if a.Position != c {
+				return nil, fmt.Errorf("invalid snapshot: alien %d is not in %s", id, city)
+			}
+			ao.positions[city] = append(ao.positions[city], a)
+		}
+	}
+
+	for city, damage := range s.Damage {
+		if findCity(w, city) == nil {
+			return nil, fmt.Errorf("invalid snapshot: damage of unknown city %q", city)
+		}
+		if damage < 0 {
+			return nil, fmt.Errorf("invalid snapshot: negative damage of %s: %d", city, damage)
+		}
+		ao.damage[city] = damage
+	}
+
+	return &ao, nil
+}
+
+// World returns the World the aliens are in.
+func (ao *AlienOrchestrator) World() *world.World {
+	return ao.world
+}
+
+// findCity returns the city with the given name, even if it was destroyed, or nil if there's none.
+func findCity(w *world.World, name string) *world.City {
+	if c, ok := w.Cities[name]; ok {
+		return c
+	}
+	for _, d := range w.DestroyedCities {
+		if d.Name == name {
+			return d
+		}
+	}
+
+	return nil
+}
+
+// countingSource is a random source that counts the values drawn from it, so that
+// its state can be saved as its seed and the amount of values drawn.
+type countingSource struct {
+	src   rand.Source64
+	seed  int64
+	draws uint64
+}
+
+// newCountingSource returns a source with the given seed, after drawing the given amount of values.
+func newCountingSource(seed int64, draws uint64) *countingSource {
+	s := &countingSource{src: rand.NewSource(seed).(rand.Source64), seed: seed}
+	for s.draws < draws {
+		s.Int63()
+	}
+
+	return s
+}
+
+func (s *countingSource) Int63() int64 {
+	s.draws++
+	return s.src.Int63()
+}
+
+func (s *countingSource) Uint64() uint64 {
+	s.draws++
+	return s.src.Uint64()
+}
+
+func (s *countingSource) Seed(seed int64) {
+	s.src.Seed(seed)
+	s.seed = seed
+	s.draws = 0
+}
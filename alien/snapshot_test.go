@@ -0,0 +1,174 @@
+package alien
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/santihernandezc/alien-invasion/world"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshot(t *testing.T) {
+	worldDef := "A north=B east=C\nB east=D\nC north=D south=E\nD east=F\nE west=F\nF south=G\nG south=D"
+
+	probabilistic := DefaultRules()
+	probabilistic.SurvivalProbability = 0.5
+	probabilistic.DestroyCity = false
+
+	simultaneous := DefaultRules()
+	simultaneous.TurnModel = TurnSimultaneous
+	simultaneous.CrossingFights = true
+
+	roadsLost := probabilistic
+	roadsLost.RoadsLost = 1
+
+	tests := []struct {
+		name  string
+		rules Rules
+		// steps is the amount of aliens moved before saving
+		steps int
+	}{
+		{"default rules, in the middle of a turn", DefaultRules(), 5},
+		{"default rules, at the start of a turn", DefaultRules(), 0},
+		{"survival probability", probabilistic, 13},
+		{"simultaneous turns", simultaneous, 2},
+		{"roads lost, with isolated aliens", roadsLost, 20},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			for seed := int64(1); seed <= 10; seed++ {
+				w, err := world.NewFromReader(strings.NewReader(worldDef), false)
+				if !assert.NoError(tt, err) {
+					return
+				}
+				ao, err := NewOrchestrator(8, seed, w, test.rules, nopLogger)
+				if !assert.NoError(tt, err) {
+					return
+				}
+				if !assert.NoError(tt, AssignStrategies(ao.Aliens, []string{StrategyAvoidRecent, StrategySeekNearest, StrategyRandomWalk})) {
+					return
+				}
+				for i := 0; i < test.steps; i++ {
+					ao.StepAlien()
+				}
+
+				var buf bytes.Buffer
+				if !assert.NoError(tt, ao.Snapshot(&buf)) {
+					return
+				}
+				restored, err := Restore(&buf, nopLogger)
+				if !assert.NoError(tt, err) {
+					return
+				}
+
+				// Both simulations go on exactly the same way
+				record := func(ao *AlienOrchestrator) ([]Event, string) {
+					var events []Event
+					ao.Subscribe(SubscriberFunc(func(e Event) {
+						events = append(events, e)
+					}))
+					ao.Run(StopConditions{MaxTurns: 30})
+					return events, ao.World().String()
+				}
+				expectedEvents, expectedWorld := record(ao)
+				events, restoredWorld := record(restored)
+
+				assert.Equal(tt, expectedEvents, events, "seed %d", seed)
+				assert.Equal(tt, expectedWorld, restoredWorld, "seed %d", seed)
+			}
+		})
+	}
+
+	t.Run("custom strategies can't be saved", func(tt *testing.T) {
+		ao, _ := newOrchestratorWithAliens(tt, "A east=B", DefaultRules(), "A")
+		ao.Aliens[0].Strategy = testStrategy{}
+
+		assert.EqualError(tt, ao.Snapshot(&bytes.Buffer{}), "invalid strategy of alien 1: alien.testStrategy can't be saved")
+	})
+}
+
+// testStrategy is a strategy that isn't built in.
+type testStrategy struct{ RandomWalk }
+
+func TestRestore(t *testing.T) {
+	tests := []struct {
+		name     string
+		snapshot string
+		err      string
+	}{
+		{
+			"invalid JSON",
+			`{"version":`,
+			"error decoding snapshot: unexpected EOF",
+		},
+		{
+			"unsupported version",
+			`{"version": 2}`,
+			"invalid snapshot: unsupported version 2, expected 1",
+		},
+		{
+			"invalid rules",
+			`{"version": 1, "rules": {"threshold": 1}}`,
+			"invalid rules: threshold must be at least 2, got 1",
+		},
+		{
+			"road to unknown city",
+			`{"version": 1, "rules": {"threshold": 2}, "world": {"cities": [{"name": "A", "neighbors": ["B"]}]}}`,
+			`invalid snapshot: road from A to unknown city "B"`,
+		},
+		{
+			"alien in unknown city",
+			`{"version": 1, "rules": {"threshold": 2}, "aliens": [{"id": 1, "city": "B"}], "world": {"cities": [{"name": "A", "neighbors": []}]}}`,
+			`invalid snapshot: alien 1 is in unknown city "B"`,
+		},
+		{
+			"aliens in unknown city",
+			`{"version": 1, "rules": {"threshold": 2}, "positions": {"B": [1]}, "world": {"cities": [{"name": "A", "neighbors": []}]}}`,
+			`invalid snapshot: aliens in unknown city "B"`,
+		},
+		{
+			"alien in the wrong city",
+			`{"version": 1, "rules": {"threshold": 2}, "aliens": [{"id": 1, "city": "A"}], "positions": {"B": [1]}, "world": {"cities": [{"name": "A", "neighbors": ["B"]}, {"name": "B", "neighbors": ["A"]}]}}`,
+			"invalid snapshot: alien 1 is not in B",
+		},
+		{
+			"alien going to unknown city",
+			`{"version": 1, "rules": {"threshold": 2}, "aliens": [{"id": 1, "city": "A", "destination": "B", "travel": 3, "traveled": 1}], "world": {"cities": [{"name": "A", "neighbors": []}]}}`,
+			`invalid snapshot: alien 1 is going to unknown city "B"`,
+		},
+		{
+			"alien traveled too long",
+			`{"version": 1, "rules": {"threshold": 2}, "aliens": [{"id": 1, "city": "A", "destination": "B", "travel": 3, "traveled": 3}], "world": {"cities": [{"name": "A", "neighbors": ["B"]}, {"name": "B", "neighbors": ["A"]}]}}`,
+			"invalid snapshot: alien 1 traveled 3 of 3 turns",
+		},
+		{
+			"damage of unknown city",
+			`{"version": 1, "rules": {"threshold": 2}, "damage": {"B": 1}, "world": {"cities": [{"name": "A", "neighbors": []}]}}`,
+			`invalid snapshot: damage of unknown city "B"`,
+		},
+		{
+			"cursor out of turn",
+			`{"version": 1, "rules": {"threshold": 2}, "cursor": 1, "aliens": [{"id": 1, "city": "A"}, {"id": 2, "city": "A"}], "world": {"cities": [{"name": "A", "neighbors": []}]}}`,
+			"invalid snapshot: cursor 1 out of turn",
+		},
+		{
+			"cursor past the aliens",
+			`{"version": 1, "rules": {"threshold": 2}, "turn": 1, "cursor": 1, "in_turn": true, "aliens": [{"id": 1, "city": "A"}], "world": {"cities": [{"name": "A", "neighbors": []}]}}`,
+			"invalid snapshot: cursor 1 past the 1 aliens",
+		},
+		{
+			"invalid strategy",
+			`{"version": 1, "rules": {"threshold": 2}, "aliens": [{"id": 1, "city": "A", "strategy": "teleport"}], "world": {"cities": [{"name": "A", "neighbors": []}]}}`,
+			`invalid strategy: "teleport"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			_, err := Restore(strings.NewReader(test.snapshot), nopLogger)
+			assert.EqualError(tt, err, test.err)
+		})
+	}
+}
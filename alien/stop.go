@@ -0,0 +1,164 @@
+package alien
+
+import (
+	"time"
+
+	"github.com/santihernandezc/alien-invasion/world"
+)
+
+// StopReason tells why a simulation stopped.
+type StopReason string
+
+// Reasons for a simulation to stop.
+const (
+	StopNoAliens           StopReason = "no_aliens"
+	StopAllCitiesDestroyed StopReason = "all_cities_destroyed"
+	StopMaxTurns           StopReason = "max_turns"
+	StopNoMobileAliens     StopReason = "no_mobile_aliens"
+	StopAliensIsolated     StopReason = "aliens_isolated"
+	StopTimeLimit          StopReason = "time_limit"
+)
+
+// descriptions are the human-readable descriptions of each StopReason.
+var descriptions = map[StopReason]string{
+	StopNoAliens:           "there are no aliens left",
+	StopAllCitiesDestroyed: "every city was destroyed",
+	StopMaxTurns:           "the maximum number of turns was reached",
+	StopNoMobileAliens:     "no alien can move",
+	StopAliensIsolated:     "no alien can meet another one",
+	StopTimeLimit:          "the time limit was reached",
+}
+
+func (r StopReason) String() string {
+	if description, ok := descriptions[r]; ok {
+		return description
+	}
+	return string(r)
+}
+
+// StopConditions define when a simulation stops, besides running out of aliens.
+// They're only checked once every alien moved in the current turn.
+type StopConditions struct {
+	// MaxTurns is the maximum amount of turns, there's no limit if it's 0.
+	MaxTurns int
+	// AllCitiesDestroyed stops the simulation once there are no cities left.
+	AllCitiesDestroyed bool
+	// NoMobileAliens stops the simulation when every alien is in a city without roads.
+	NoMobileAliens bool
+	// AliensIsolated stops the simulation when no two aliens are in the same connected
+	// component, so they can't meet anymore. Roads are considered in both directions.
+	AliensIsolated bool
+	// TimeLimit is the maximum wall-clock duration of the simulation since the first turn,
+	// there's no limit if it's 0. It makes the simulation non-deterministic.
+	TimeLimit time.Duration
+}
+
+// Result describes a simulation after it stopped.
+type Result struct {
+	Reason StopReason
+	Turns  int
+	// Survivors are the IDs of the aliens left
+	Survivors []int
+	// DestroyedCities are the names of the destroyed cities, in the order they were destroyed
+	DestroyedCities []string
+	// Losses are the population and value lost by the cities
+	Losses Losses
+	// World is what's left of the World
+	World *world.World
+}
+
+// Stopped returns the reason why the simulation is over according to the given conditions,
+// or an empty reason if it isn't.
+func (ao *AlienOrchestrator) Stopped(stop StopConditions) StopReason {
+	if ao.inTurn {
+		return ""
+	}
+
+	switch {
+	case stop.AllCitiesDestroyed && len(ao.world.Cities) == 0:
+		return StopAllCitiesDestroyed
+	case len(ao.Aliens) < 1:
+		return StopNoAliens
+	case stop.MaxTurns > 0 && ao.turn >= stop.MaxTurns:
+		return StopMaxTurns
+	case stop.NoMobileAliens && ao.noMobileAliens():
+		return StopNoMobileAliens
+	case stop.AliensIsolated && ao.aliensIsolated():
+		return StopAliensIsolated
+	case stop.TimeLimit > 0 && !ao.started.IsZero() && time.Since(ao.started) >= stop.TimeLimit:
+		return StopTimeLimit
+	}
+
+	return ""
+}
+
+func (ao *AlienOrchestrator) noMobileAliens() bool {
+	for _, a := range ao.Aliens {
+		if a.Traveling() || len(a.Position.Neighbors) > 0 || ao.waiting(a) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// aliensIsolated reports whether every alien is alone in its connected component, counting
+// the blocked roads that will open again. Aliens on a road are in the component of the city they're going to.
+func (ao *AlienOrchestrator) aliensIsolated() bool {
+	// Roads are traversed both ways, so build the reverse roads of directed worlds
+	adjacent := make(map[*world.City][]*world.City, len(ao.world.Cities))
+	for _, city := range ao.world.Cities {
+		for _, n := range city.Neighbors {
+			adjacent[city] = append(adjacent[city], n)
+			adjacent[n] = append(adjacent[n], city)
+		}
+	}
+
+	// Blocked roads will take aliens to the other end once they open
+	for _, r := range ao.reopening() {
+		adjacent[r.From] = append(adjacent[r.From], r.To)
+		adjacent[r.To] = append(adjacent[r.To], r.From)
+	}
+
+	traveling := make(map[*world.City]int)
+	for _, a := range ao.Aliens {
+		if a.Traveling() {
+			traveling[a.Destination]++
+		}
+	}
+
+	visited := make(map[*world.City]bool, len(ao.world.Cities))
+	for _, a := range ao.Aliens {
+		start := a.Position
+		if a.Traveling() {
+			start = a.Destination
+		}
+		if visited[start] {
+			// Another alien's component already reached this city
+			return false
+		}
+
+		// Flood the component of the alien
+		queue := []*world.City{start}
+		visited[start] = true
+		aliens := 0
+		for len(queue) > 0 {
+			city := queue[0]
+			queue = queue[1:]
+			aliens += ao.AliensIn(city.Name) + traveling[city]
+
+			for _, n := range adjacent[city] {
+				if !visited[n] {
+					visited[n] = true
+					queue = append(queue, n)
+				}
+			}
+		}
+
+		if aliens > 1 {
+			return false
+		}
+	}
+
+	return true
+}
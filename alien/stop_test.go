@@ -0,0 +1,114 @@
+package alien
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStopped(t *testing.T) {
+	tests := []struct {
+		name     string
+		worldDef string
+		// cities where the aliens are placed
+		cities   []string
+		stop     StopConditions
+		expected StopReason
+	}{
+		{
+			"no aliens",
+			"A east=B",
+			nil,
+			StopConditions{},
+			StopNoAliens,
+		},
+		{
+			"aliens can still meet",
+			"A east=B",
+			[]string{"A", "B"},
+			StopConditions{NoMobileAliens: true, AliensIsolated: true},
+			"",
+		},
+		{
+			"aliens can't move",
+			"A\nB",
+			[]string{"A", "B"},
+			StopConditions{NoMobileAliens: true},
+			StopNoMobileAliens,
+		},
+		{
+			"aliens in different components",
+			"A east=B\nC east=D",
+			[]string{"A", "D"},
+			StopConditions{AliensIsolated: true},
+			StopAliensIsolated,
+		},
+		{
+			"aliens in the same component, not isolated",
+			"A east=B\nB east=C\nD",
+			[]string{"A", "C", "D"},
+			StopConditions{AliensIsolated: true},
+			"",
+		},
+		{
+			"isolation is not checked unless asked",
+			"A east=B\nC east=D",
+			[]string{"A", "D"},
+			StopConditions{NoMobileAliens: true},
+			"",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			ao, _ := newOrchestratorWithAliens(tt, test.worldDef, DefaultRules(), test.cities...)
+			assert.Equal(tt, test.expected, ao.Stopped(test.stop))
+		})
+	}
+	t.Run("aliens behind blocked roads can still move and meet", func(tt *testing.T) {
+		ao, w := newOrchestratorWithAliens(tt, "A east=B", DefaultRules(), "A", "B")
+		_, err := w.BlockRoad(w.Cities["A"], w.Cities["B"], 2)
+		if !assert.NoError(tt, err) {
+			return
+		}
+
+		stop := StopConditions{NoMobileAliens: true, AliensIsolated: true}
+		assert.Equal(tt, StopReason(""), ao.Stopped(stop))
+
+		// Unless the road is destroyed for good
+		_, err = w.UnblockRoad(w.Cities["A"], w.Cities["B"])
+		if !assert.NoError(tt, err) {
+			return
+		}
+		_, err = w.DestroyRoad(w.Cities["A"], w.Cities["B"])
+		if !assert.NoError(tt, err) {
+			return
+		}
+		assert.Equal(tt, StopNoMobileAliens, ao.Stopped(stop))
+	})
+}
+
+func TestRunResult(t *testing.T) {
+	t.Run("stops after the maximum amount of turns", func(tt *testing.T) {
+		ao, w := newOrchestratorWithAliens(tt, "A east=B\nC east=D", DefaultRules(), "A", "C")
+		result := ao.Run(StopConditions{MaxTurns: 5})
+
+		assert.Equal(tt, Result{Reason: StopMaxTurns, Turns: 5, Survivors: []int{1, 2}, DestroyedCities: []string{}, World: w}, result)
+	})
+
+	t.Run("stops early when aliens are isolated", func(tt *testing.T) {
+		ao, _ := newOrchestratorWithAliens(tt, "A east=B\nC east=D", DefaultRules(), "A", "C")
+		result := ao.Run(StopConditions{MaxTurns: 5, AliensIsolated: true})
+
+		assert.Equal(tt, StopAliensIsolated, result.Reason)
+		assert.Equal(tt, 0, result.Turns)
+	})
+
+	t.Run("reports destroyed cities", func(tt *testing.T) {
+		ao, w := newOrchestratorWithAliens(tt, "A east=B", DefaultRules(), "A", "B")
+		result := ao.Run(StopConditions{MaxTurns: 5})
+
+		losses := Losses{Cities: []CityLosses{{City: "B", Destroyed: true, Damage: 1}}}
+		assert.Equal(tt, Result{Reason: StopNoAliens, Turns: 1, Survivors: []int{}, DestroyedCities: []string{"B"}, Losses: losses, World: w}, result)
+	})
+}
@@ -0,0 +1,245 @@
+package alien
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+
+	"github.com/santihernandezc/alien-invasion/world"
+)
+
+// Names of the built-in strategies.
+const (
+	StrategyRandomWalk   = "random"
+	StrategyAvoidRecent  = "avoid-recent"
+	StrategySeekNearest  = "seek"
+	StrategyFlee         = "flee"
+	StrategyPreferDegree = "high-degree"
+)
+
+// recentCities is how many cities an AvoidRecent strategy remembers.
+const recentCities = 3
+
+// View is what a Strategy can see of the simulation besides the alien it moves.
+type View interface {
+	// AliensIn returns the amount of aliens in a city.
+	AliensIn(city string) int
+	// TravelTime returns the amount of turns it takes to travel the road between two cities.
+	TravelTime(from, to *world.City) int
+}
+
+// Strategy decides where an alien goes next.
+type Strategy interface {
+	// Next returns one of the neighbors of the alien's current city, which has at least one.
+	// The view might be nil, in which case the strategy can't see other aliens.
+	Next(a *Alien, v View, rng *rand.Rand) *world.City
+}
+
+// NewStrategy returns a new instance of the built-in strategy with the given name.
+// Each alien needs its own instance, since strategies can keep state.
+func NewStrategy(name string) (Strategy, error) {
+	switch name {
+	case StrategyRandomWalk:
+		return RandomWalk{}, nil
+	case StrategyAvoidRecent:
+		return &AvoidRecent{}, nil
+	case StrategySeekNearest:
+		return SeekNearest{}, nil
+	case StrategyFlee:
+		return Flee{}, nil
+	case StrategyPreferDegree:
+		return PreferDegree{}, nil
+	}
+
+	return nil, fmt.Errorf("invalid strategy: %q", name)
+}
+
+// AssignStrategies gives each alien a new instance of a strategy from the list,
+// in order and starting over when the list is exhausted.
+func AssignStrategies(aliens []*Alien, names []string) error {
+	if len(names) == 0 {
+		return fmt.Errorf("invalid strategies: empty list")
+	}
+
+	for i, a := range aliens {
+		strategy, err := NewStrategy(strings.TrimSpace(names[i%len(names)]))
+		if err != nil {
+			return err
+		}
+		a.Strategy = strategy
+	}
+
+	return nil
+}
+
+// RandomWalk moves to any neighbor with the same probability.
+type RandomWalk struct{}
+
+// Next returns a random neighbor.
+func (RandomWalk) Next(a *Alien, v View, rng *rand.Rand) *world.City {
+	neighbors := a.Position.Neighbors
+	return neighbors[rng.Intn(len(neighbors))]
+}
+
+// AvoidRecent walks randomly but avoids the last cities it visited, unless there's no other choice.
+type AvoidRecent struct {
+	recent []*world.City
+}
+
+// Next returns a random neighbor among the ones that weren't recently visited.
+func (s *AvoidRecent) Next(a *Alien, v View, rng *rand.Rand) *world.City {
+	// Remember where the alien is leaving from
+	s.recent = append(s.recent, a.Position)
+	if len(s.recent) > recentCities {
+		s.recent = s.recent[1:]
+	}
+
+	candidates := make([]*world.City, 0, len(a.Position.Neighbors))
+	for _, n := range a.Position.Neighbors {
+		if !s.visited(n) {
+			candidates = append(candidates, n)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return RandomWalk{}.Next(a, v, rng)
+	}
+	return candidates[rng.Intn(len(candidates))]
+}
+
+func (s *AvoidRecent) visited(city *world.City) bool {
+	for _, c := range s.recent {
+		if c == city {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SeekNearest moves along the fastest path to the nearest city with other aliens,
+// walking randomly if it can't reach any.
+type SeekNearest struct{}
+
+// Next returns a random neighbor among the ones closest to another alien.
+func (SeekNearest) Next(a *Alien, v View, rng *rand.Rand) *world.City {
+	return pickByDistance(a, v, rng, func(distance, best int) bool { return distance < best })
+}
+
+// Flee moves away from other aliens, to the neighbor farthest from the nearest of them.
+type Flee struct{}
+
+// Next returns a random neighbor among the ones farthest from other aliens.
+func (Flee) Next(a *Alien, v View, rng *rand.Rand) *world.City {
+	return pickByDistance(a, v, rng, func(distance, best int) bool { return distance > best })
+}
+
+// pickByDistance returns a random neighbor among the ones whose distance to the
+// nearest other alien, in turns and going through them, is the best according to better.
+// Neighbors that can't reach other aliens are considered infinitely far.
+func pickByDistance(a *Alien, v View, rng *rand.Rand, better func(distance, best int) bool) *world.City {
+	var candidates []*world.City
+	var best int
+	for i, n := range a.Position.Neighbors {
+		distance := distanceToAlien(a, v, n)
+		if distance != unreachable {
+			distance += v.TravelTime(a.Position, n)
+		}
+		switch {
+		case i == 0 || better(distance, best):
+			candidates = []*world.City{n}
+			best = distance
+		case distance == best:
+			candidates = append(candidates, n)
+		}
+	}
+
+	return candidates[rng.Intn(len(candidates))]
+}
+
+// unreachable is the distance to aliens that can't be reached.
+const unreachable = math.MaxInt
+
+// distanceToAlien returns the amount of turns it takes to travel from a city to the nearest
+// city with aliens other than a, or unreachable if there's none.
+func distanceToAlien(a *Alien, v View, from *world.City) int {
+	if v == nil {
+		return unreachable
+	}
+
+	others := func(city *world.City) int {
+		amount := v.AliensIn(city.Name)
+		if city == a.Position {
+			amount--
+		}
+		return amount
+	}
+
+	// Dijkstra's algorithm from the city, which is a breadth-first search when every road takes one turn
+	distances := map[*world.City]int{from: 0}
+	queue := &cityQueue{{city: from}}
+	for queue.Len() > 0 {
+		current := heap.Pop(queue).(queuedCity)
+		if current.distance > distances[current.city] {
+			// Already reached in fewer turns
+			continue
+		}
+		if others(current.city) > 0 {
+			return current.distance
+		}
+
+		for _, n := range current.city.Neighbors {
+			distance := current.distance + v.TravelTime(current.city, n)
+			if d, ok := distances[n]; !ok || distance < d {
+				distances[n] = distance
+				heap.Push(queue, queuedCity{city: n, distance: distance})
+			}
+		}
+	}
+
+	return unreachable
+}
+
+// queuedCity is a city reached in the given amount of turns.
+type queuedCity struct {
+	city     *world.City
+	distance int
+}
+
+// cityQueue is a priority queue of cities, closest first, to be used with container/heap.
+type cityQueue []queuedCity
+
+func (q cityQueue) Len() int            { return len(q) }
+func (q cityQueue) Less(i, j int) bool  { return q[i].distance < q[j].distance }
+func (q cityQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *cityQueue) Push(x interface{}) { *q = append(*q, x.(queuedCity)) }
+func (q *cityQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// PreferDegree walks randomly, choosing neighbors with more roads more often.
+type PreferDegree struct{}
+
+// Next returns a random neighbor with a probability proportional to its amount of roads plus one.
+func (PreferDegree) Next(a *Alien, v View, rng *rand.Rand) *world.City {
+	var total int
+	for _, n := range a.Position.Neighbors {
+		total += len(n.Neighbors) + 1
+	}
+
+	pick := rng.Intn(total)
+	for _, n := range a.Position.Neighbors {
+		pick -= len(n.Neighbors) + 1
+		if pick < 0 {
+			return n
+		}
+	}
+
+	// Unreachable, the picks add up to the total
+	return a.Position.Neighbors[len(a.Position.Neighbors)-1]
+}
@@ -0,0 +1,173 @@
+package alien
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/santihernandezc/alien-invasion/world"
+	"github.com/stretchr/testify/assert"
+)
+
+// testView is a View with a fixed amount of aliens per city.
+type testView map[string]int
+
+func (v testView) AliensIn(city string) int {
+	return v[city]
+}
+
+func (v testView) TravelTime(from, to *world.City) int {
+	return 1
+}
+
+func TestStrategies(t *testing.T) {
+	// A line of cities: A - B - C - D - E
+	w, err := world.NewFromReader(strings.NewReader("A east=B\nB east=C\nC east=D\nD east=E"), false)
+	if !assert.NoError(t, err) {
+		return
+	}
+	a, b, c := w.Cities["A"], w.Cities["B"], w.Cities["C"]
+
+	tests := []struct {
+		name     string
+		strategy func() Strategy
+		position *world.City
+		view     View
+		possible []*world.City
+	}{
+		{
+			"random walk",
+			func() Strategy { return RandomWalk{} },
+			b,
+			nil,
+			[]*world.City{a, c},
+		},
+		{
+			"seek nearest alien",
+			func() Strategy { return SeekNearest{} },
+			b,
+			testView{"B": 1, "E": 1},
+			[]*world.City{c},
+		},
+		{
+			"seek nearest alien, aliens in both directions",
+			func() Strategy { return SeekNearest{} },
+			c,
+			testView{"A": 1, "C": 1, "E": 1},
+			[]*world.City{b, w.Cities["D"]},
+		},
+		{
+			"seek nearest alien, no other aliens",
+			func() Strategy { return SeekNearest{} },
+			b,
+			testView{"B": 1},
+			[]*world.City{a, c},
+		},
+		{
+			"flee other aliens",
+			func() Strategy { return Flee{} },
+			b,
+			testView{"B": 1, "D": 1},
+			[]*world.City{a},
+		},
+		{
+			"flee other aliens, alien in the same city",
+			func() Strategy { return Flee{} },
+			b,
+			testView{"B": 2},
+			[]*world.City{a, c},
+		},
+		{
+			"avoid recent, coming from A",
+			func() Strategy { return &AvoidRecent{recent: []*world.City{a}} },
+			b,
+			nil,
+			[]*world.City{c},
+		},
+		{
+			"avoid recent, every neighbor visited",
+			func() Strategy { return &AvoidRecent{recent: []*world.City{a, c}} },
+			b,
+			nil,
+			[]*world.City{a, c},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			rng := rand.New(rand.NewSource(0))
+			for i := 0; i < 20; i++ {
+				alien := &Alien{Position: test.position}
+				assert.Contains(tt, test.possible, test.strategy().Next(alien, test.view, rng))
+			}
+		})
+	}
+}
+
+func TestSeekNearestTravelTime(t *testing.T) {
+	// P is two roads away from X, through A, and R is three roads away, through B,
+	// but the road from A to P takes much longer than the ones through B
+	worldDef := `[
+		{"name": "X", "neighbors": ["A", "B"]},
+		{"name": "A", "neighbors": [{"name": "P", "length": 10}]},
+		{"name": "B", "neighbors": ["Q"]},
+		{"name": "Q", "neighbors": ["R"]}
+	]`
+
+	tests := []struct {
+		name     string
+		speed    float64
+		expected string
+	}{
+		{"every road takes one turn", 0, "A"},
+		{"roads take turns by length", 1, "B"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			rules := DefaultRules()
+			rules.Speed = test.speed
+			ao, _ := newOrchestratorWithAliens(tt, worldDef, rules, "X", "P", "R")
+
+			rng := rand.New(rand.NewSource(0))
+			for i := 0; i < 20; i++ {
+				assert.Equal(tt, test.expected, SeekNearest{}.Next(ao.Aliens[0], ao, rng).Name)
+			}
+		})
+	}
+}
+
+func TestPreferDegree(t *testing.T) {
+	// B is a hub with four roads, C is a dead end
+	w, err := world.NewFromReader(strings.NewReader("A north=B south=C\nB north=D east=E west=F"), false)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	rng := rand.New(rand.NewSource(0))
+	picks := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		picks[PreferDegree{}.Next(&Alien{Position: w.Cities["A"]}, nil, rng).Name]++
+	}
+
+	assert.Equal(t, 1000, picks["B"]+picks["C"])
+	assert.Greater(t, picks["B"], 2*picks["C"])
+}
+
+func TestAssignStrategies(t *testing.T) {
+	aliens := []*Alien{{ID: 1}, {ID: 2}, {ID: 3}}
+
+	err := AssignStrategies(aliens, []string{StrategyFlee, StrategyAvoidRecent})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, Flee{}, aliens[0].Strategy)
+	assert.Equal(t, &AvoidRecent{}, aliens[1].Strategy)
+	assert.Equal(t, Flee{}, aliens[2].Strategy)
+
+	// Stateful strategies are not shared
+	assert.NotSame(t, aliens[1].Strategy, aliens[0].Strategy)
+
+	assert.EqualError(t, AssignStrategies(aliens, nil), "invalid strategies: empty list")
+	assert.EqualError(t, AssignStrategies(aliens, []string{"teleport"}), `invalid strategy: "teleport"`)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/santihernandezc/alien-invasion/world"
+	"github.com/santihernandezc/alien-invasion/world/analysis"
+)
+
+// centralCities is how many of the most central cities the analysis reports.
+const centralCities = 5
+
+// runAnalyze reports the structure of a map: how its cities are connected,
+// which ones hold it together and which ones aliens are most likely to go through.
+func runAnalyze(args []string) int {
+	fs := flag.NewFlagSet("analyze", flag.ExitOnError)
+	path := fs.String("path", "config.json", "path to the map file, in json or text format")
+	directed := fs.Bool("directed", false, "use a directed graph")
+	parseFlags(fs, args)
+
+	log := log.New(os.Stdout, "", 0)
+
+	w, err := loadWorld(*path, *directed)
+	if err != nil {
+		log.Printf("Error reading and parsing file: %v", err)
+		return exitError
+	}
+
+	log.Printf("Cities: %d", len(w.Cities))
+	logComponents("Components", analysis.WeakComponents(w), log)
+	if w.Directed() {
+		logComponents("Strong components", analysis.StrongComponents(w), log)
+	}
+	log.Printf("Articulation points: %s", joinCities(analysis.ArticulationPoints(w), ", "))
+
+	var bridges []string
+	for _, r := range analysis.Bridges(w) {
+		bridges = append(bridges, r.From.Name+"-"+r.To.Name)
+	}
+	log.Printf("Bridges: %s", orNone(strings.Join(bridges, ", ")))
+
+	diameter, longest := analysis.Diameter(w)
+	log.Printf("Diameter: %d roads, %s", diameter, joinCities(longest, " -> "))
+
+	distribution := analysis.DegreeDistribution(w)
+	degrees := make([]int, 0, len(distribution))
+	for d := range distribution {
+		degrees = append(degrees, d)
+	}
+	sort.Ints(degrees)
+	counts := make([]string, len(degrees))
+	for i, d := range degrees {
+		counts[i] = fmt.Sprintf("%d roads: %d cities", d, distribution[d])
+	}
+	log.Printf("Degree distribution: %s", orNone(strings.Join(counts, ", ")))
+
+	log.Printf("Most central cities: %s", orNone(mostCentral(analysis.Betweenness(w))))
+
+	return exitOK
+}
+
+func logComponents(title string, components [][]*world.City, log *log.Logger) {
+	largest := 0
+	for _, c := range components {
+		if len(c) > largest {
+			largest = len(c)
+		}
+	}
+	log.Printf("%s: %d, the largest with %d cities", title, len(components), largest)
+}
+
+// mostCentral lists the cities with the highest betweenness, along with it.
+func mostCentral(betweenness map[*world.City]float64) string {
+	cities := make([]*world.City, 0, len(betweenness))
+	for city := range betweenness {
+		cities = append(cities, city)
+	}
+	sort.Slice(cities, func(i, j int) bool {
+		if betweenness[cities[i]] != betweenness[cities[j]] {
+			return betweenness[cities[i]] > betweenness[cities[j]]
+		}
+		return cities[i].Name < cities[j].Name
+	})
+	if len(cities) > centralCities {
+		cities = cities[:centralCities]
+	}
+
+	central := make([]string, len(cities))
+	for i, city := range cities {
+		central[i] = fmt.Sprintf("%s (%.2f)", city.Name, betweenness[city])
+	}
+	return strings.Join(central, ", ")
+}
+
+func joinCities(cities []*world.City, sep string) string {
+	names := make([]string, len(cities))
+	for i, city := range cities {
+		names[i] = city.Name
+	}
+	return orNone(strings.Join(names, sep))
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/santihernandezc/alien-invasion/alien"
+	"github.com/santihernandezc/alien-invasion/batch"
+	"github.com/santihernandezc/alien-invasion/world"
+)
+
+// runBatch runs many headless simulations in parallel and reports aggregated statistics.
+func runBatch(args []string) int {
+	fs := flag.NewFlagSet("batch", flag.ExitOnError)
+	path := fs.String("path", "config.json", "path to the map file, in json or text format")
+	directed := fs.Bool("directed", false, "use a directed graph")
+	n := fs.Int("n", 5, "number of aliens for each simulation")
+	strategy := fs.String("strategy", alien.StrategyRandomWalk, "comma-separated movement strategies, assigned to aliens in order")
+	runs := fs.Int("runs", 1000, "number of simulations")
+	seed := fs.Int64("seed", 0, "seed to derive the seed of each simulation, a random one is used if 0")
+	workers := fs.Int("workers", 0, "number of simulations running in parallel, defaults to the number of CPUs")
+	format := fs.String("format", "table", "output format: table, csv (one row per simulation) or json")
+	rules := addRulesFlags(fs)
+	stop := addStopFlags(fs)
+	parseFlags(fs, args)
+
+	// Statistics go to stdout, so the rest goes to stderr
+	log := log.New(os.Stderr, "", 0)
+
+	// Check the format before running the simulations, which might take a while
+	switch *format {
+	case "table", "csv", "json":
+	default:
+		log.Printf("Invalid output format %q", *format)
+		return exitError
+	}
+
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	log.Printf("Using seed %d", *seed)
+
+	// Read the map once and parse it for every simulation
+	b, err := os.ReadFile(*path)
+	if err != nil {
+		log.Printf("Error opening file in path %s: %v", *path, err)
+		return exitError
+	}
+	newWorld := func() (*world.World, error) {
+		return parseWorld(*path, b, *directed)
+	}
+
+	worldMap, err := newWorld()
+	if err != nil {
+		log.Printf("Error reading and parsing file: %v", err)
+		return exitError
+	}
+	var cities []string
+	for _, city := range worldMap.SortedCities() {
+		cities = append(cities, city.Name)
+	}
+
+	log.Printf("Running %d simulations with %d aliens", *runs, *n)
+	results, err := batch.Run(batch.Config{
+		NewWorld:   newWorld,
+		Runs:       *runs,
+		Aliens:     *n,
+		Strategies: strings.Split(*strategy, ","),
+		Rules:      *rules,
+		Stop:       *stop,
+		Seed:       *seed,
+		Workers:    *workers,
+	})
+	if err != nil {
+		log.Printf("Error running simulations: %v", err)
+		return exitError
+	}
+
+	summary := batch.Summarize(results, cities)
+	switch *format {
+	case "table":
+		err = summary.WriteTable(os.Stdout)
+	case "csv":
+		err = summary.WriteCSV(os.Stdout)
+	case "json":
+		err = summary.WriteJSON(os.Stdout)
+	}
+
+	if err != nil {
+		log.Printf("Error writing statistics: %v", err)
+		return exitError
+	}
+	return exitOK
+}
@@ -0,0 +1,156 @@
+// Package batch runs many independent simulations on the same map
+// and aggregates their results.
+package batch
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/santihernandezc/alien-invasion/alien"
+	"github.com/santihernandezc/alien-invasion/world"
+)
+
+var nopLogger = log.New(io.Discard, "", 0)
+
+// Config defines how to run a batch of simulations.
+type Config struct {
+	// NewWorld returns a new World for each simulation, since simulations destroy their World.
+	NewWorld func() (*world.World, error)
+	// Runs is the amount of simulations to run.
+	Runs int
+	// Aliens is the amount of aliens in each simulation.
+	Aliens int
+	// Strategies are the movement strategies assigned to the aliens in order,
+	// aliens walk randomly if it's empty.
+	Strategies []string
+	// Rules are the combat rules of every simulation, defaults to alien.DefaultRules.
+	Rules alien.Rules
+	// Stop are the conditions that stop each simulation, which must have a maximum amount of turns or a time limit.
+	Stop alien.StopConditions
+	// Seed is used to derive the seed of each simulation.
+	Seed int64
+	// Workers is the amount of simulations running in parallel, defaults to the number of CPUs.
+	Workers int
+}
+
+// RunResult is the outcome of a single simulation.
+type RunResult struct {
+	Seed            int64    `json:"seed"`
+	Turns           int      `json:"turns"`
+	CitiesDestroyed []string `json:"cities_destroyed"`
+	// Killed are the aliens that died fighting
+	Killed int `json:"killed"`
+	// Trapped are the aliens that can't move anymore, including the ones in the ruins of a city
+	// and the ones retired because they can't meet any other alien
+	Trapped   int `json:"trapped"`
+	Survivors int `json:"survivors"`
+	// PopulationLost is the population of the destroyed cities plus the one lost by damaged cities
+	PopulationLost int `json:"population_lost"`
+	// Reason is why the simulation stopped
+	Reason alien.StopReason `json:"reason"`
+}
+
+// Run runs all the simulations and returns their results, in the same order as their seeds.
+// The results only depend on the configuration, regardless of the amount of workers.
+func Run(cfg Config) ([]RunResult, error) {
+	if cfg.NewWorld == nil {
+		return nil, fmt.Errorf("invalid NewWorld value: <nil>")
+	}
+	if cfg.Runs < 1 {
+		return nil, fmt.Errorf("invalid amount of runs: %d", cfg.Runs)
+	}
+	if cfg.Stop.MaxTurns < 1 && cfg.Stop.TimeLimit <= 0 {
+		return nil, fmt.Errorf("invalid stop conditions: simulations might never stop")
+	}
+
+	workers := cfg.Workers
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	if cfg.Rules == (alien.Rules{}) {
+		cfg.Rules = alien.DefaultRules()
+	}
+
+	// Derive the seeds up front so they don't depend on the order the runs are executed
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	seeds := make([]int64, cfg.Runs)
+	for i := range seeds {
+		seeds[i] = rng.Int63()
+	}
+
+	results := make([]RunResult, cfg.Runs)
+	errs := make([]error, cfg.Runs)
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i], errs[i] = runOne(cfg, seeds[i])
+			}
+		}()
+	}
+
+	for i := range seeds {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("error in run %d: %w", i+1, err)
+		}
+	}
+
+	return results, nil
+}
+
+func runOne(cfg Config, seed int64) (RunResult, error) {
+	w, err := cfg.NewWorld()
+	if err != nil {
+		return RunResult{}, err
+	}
+
+	ao, err := alien.NewOrchestrator(cfg.Aliens, seed, w, cfg.Rules, nopLogger)
+	if err != nil {
+		return RunResult{}, err
+	}
+
+	if len(cfg.Strategies) > 0 {
+		if err := alien.AssignStrategies(ao.Aliens, cfg.Strategies); err != nil {
+			return RunResult{}, err
+		}
+	}
+
+	result := RunResult{Seed: seed}
+	ao.Subscribe(alien.SubscriberFunc(func(e alien.Event) {
+		switch e := e.(type) {
+		case alien.AliensKilled:
+			result.Killed += len(e.AlienIDs)
+		case alien.AlienTrapped:
+			result.Trapped++
+		case alien.AlienIsolated:
+			if e.Retired {
+				result.Trapped++
+			}
+		}
+	}))
+
+	end := ao.Run(cfg.Stop)
+	result.Turns = end.Turns
+	result.Survivors = len(end.Survivors)
+	result.Reason = end.Reason
+	result.CitiesDestroyed = end.DestroyedCities
+	result.PopulationLost = end.Losses.Population
+	sort.Strings(result.CitiesDestroyed)
+
+	return result, nil
+}
@@ -0,0 +1,88 @@
+package batch
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/santihernandezc/alien-invasion/alien"
+	"github.com/santihernandezc/alien-invasion/world"
+	"github.com/stretchr/testify/assert"
+)
+
+const worldDef = "A north=B east=C\nB east=D\nC north=D south=E\nD east=F\nE west=F\nF south=G\nG south=D"
+
+func newWorld() (*world.World, error) {
+	return world.NewFromReader(strings.NewReader(worldDef), false)
+}
+
+func TestRun(t *testing.T) {
+	t.Run("invalid config", func(tt *testing.T) {
+		_, err := Run(Config{Runs: 1})
+		assert.EqualError(tt, err, "invalid NewWorld value: <nil>")
+
+		_, err = Run(Config{NewWorld: newWorld})
+		assert.EqualError(tt, err, "invalid amount of runs: 0")
+
+		_, err = Run(Config{NewWorld: newWorld, Runs: 1})
+		assert.EqualError(tt, err, "invalid stop conditions: simulations might never stop")
+	})
+
+	t.Run("results don't depend on the amount of workers", func(tt *testing.T) {
+		cfg := Config{NewWorld: newWorld, Runs: 50, Aliens: 4, Stop: alien.StopConditions{MaxTurns: 100}, Seed: 7, Workers: 1}
+		expected, err := Run(cfg)
+		if !assert.NoError(tt, err) {
+			return
+		}
+
+		cfg.Workers = 8
+		results, err := Run(cfg)
+		if !assert.NoError(tt, err) {
+			return
+		}
+		assert.Equal(tt, expected, results)
+
+		// Every alien is accounted for
+		for _, r := range results {
+			assert.Equal(tt, cfg.Aliens, r.Killed+r.Trapped+r.Survivors)
+			assert.GreaterOrEqual(tt, r.Killed, 2*len(r.CitiesDestroyed))
+		}
+	})
+}
+
+func TestSummarize(t *testing.T) {
+	results := []RunResult{
+		{Seed: 1, Turns: 10, CitiesDestroyed: []string{"A", "B"}, Killed: 4, Trapped: 1, Survivors: 0, PopulationLost: 1500, Reason: alien.StopNoAliens},
+		{Seed: 2, Turns: 20, CitiesDestroyed: []string{"A"}, Killed: 2, Trapped: 2, Survivors: 1, PopulationLost: 1000, Reason: alien.StopMaxTurns},
+		{Seed: 3, Turns: 30, CitiesDestroyed: nil, Killed: 0, Trapped: 3, Survivors: 2, Reason: alien.StopMaxTurns},
+		{Seed: 4, Turns: 40, CitiesDestroyed: []string{"C"}, Killed: 2, Trapped: 3, Survivors: 0, PopulationLost: 500, Reason: alien.StopNoAliens},
+	}
+
+	summary := Summarize(results, []string{"A", "B", "C", "D"})
+
+	assert.Equal(t, 4, summary.Runs)
+	assert.Equal(t, Distribution{Min: 10, Max: 40, Mean: 25, StdDev: 11.180339887498949, Median: 20, P90: 40}, summary.Turns)
+	assert.Equal(t, 1.0, summary.CitiesDestroyed.Mean)
+	assert.Equal(t, 750.0, summary.PopulationLost.Mean)
+	assert.Equal(t, []CityDestruction{
+		{City: "A", Probability: 0.5},
+		{City: "B", Probability: 0.25},
+		{City: "C", Probability: 0.25},
+		{City: "D", Probability: 0},
+	}, summary.Cities)
+	assert.Equal(t, map[alien.StopReason]int{alien.StopNoAliens: 2, alien.StopMaxTurns: 2}, summary.Reasons)
+
+	var buf bytes.Buffer
+	if assert.NoError(t, summary.WriteCSV(&buf)) {
+		expected := "run,seed,turns,cities_destroyed,killed,trapped,survivors,population_lost,reason,destroyed\n" +
+			"1,1,10,2,4,1,0,1500,no_aliens,A;B\n" +
+			"2,2,20,1,2,2,1,1000,max_turns,A\n" +
+			"3,3,30,0,0,3,2,0,max_turns,\n" +
+			"4,4,40,1,2,3,0,500,no_aliens,C\n"
+		assert.Equal(t, expected, buf.String())
+	}
+
+	buf.Reset()
+	assert.NoError(t, summary.WriteTable(&buf))
+	assert.Contains(t, buf.String(), "50.0%")
+}
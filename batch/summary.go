@@ -0,0 +1,224 @@
+package batch
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/santihernandezc/alien-invasion/alien"
+)
+
+// Distribution describes the values of a metric across simulations.
+type Distribution struct {
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"stddev"`
+	Median float64 `json:"median"`
+	P90    float64 `json:"p90"`
+}
+
+// CityDestruction is how often a city was destroyed across simulations.
+type CityDestruction struct {
+	City        string  `json:"city"`
+	Probability float64 `json:"probability"`
+}
+
+// Summary aggregates the results of a batch of simulations.
+type Summary struct {
+	Runs            int          `json:"runs"`
+	Turns           Distribution `json:"turns"`
+	CitiesDestroyed Distribution `json:"cities_destroyed"`
+	Killed          Distribution `json:"killed"`
+	Trapped         Distribution `json:"trapped"`
+	Survivors       Distribution `json:"survivors"`
+	PopulationLost  Distribution `json:"population_lost"`
+	// Reasons counts the simulations that stopped for each reason
+	Reasons map[alien.StopReason]int `json:"reasons"`
+	// Cities has every city in the map, sorted from most to least likely to be destroyed
+	Cities  []CityDestruction `json:"cities"`
+	Results []RunResult       `json:"results"`
+}
+
+// Summarize aggregates the results of a batch on a map with the given cities.
+// Cities are needed to report the ones that were never destroyed.
+func Summarize(results []RunResult, cities []string) Summary {
+	summary := Summary{
+		Runs:    len(results),
+		Reasons: make(map[alien.StopReason]int),
+		Results: results,
+	}
+
+	metric := func(value func(r RunResult) int) Distribution {
+		values := make([]float64, len(results))
+		for i, r := range results {
+			values[i] = float64(value(r))
+		}
+		return newDistribution(values)
+	}
+	summary.Turns = metric(func(r RunResult) int { return r.Turns })
+	summary.CitiesDestroyed = metric(func(r RunResult) int { return len(r.CitiesDestroyed) })
+	summary.Killed = metric(func(r RunResult) int { return r.Killed })
+	summary.Trapped = metric(func(r RunResult) int { return r.Trapped })
+	summary.Survivors = metric(func(r RunResult) int { return r.Survivors })
+	summary.PopulationLost = metric(func(r RunResult) int { return r.PopulationLost })
+
+	for _, r := range results {
+		summary.Reasons[r.Reason]++
+	}
+
+	destroyed := make(map[string]int, len(cities))
+	for _, r := range results {
+		for _, city := range r.CitiesDestroyed {
+			destroyed[city]++
+		}
+	}
+
+	for _, city := range cities {
+		var probability float64
+		if len(results) > 0 {
+			probability = float64(destroyed[city]) / float64(len(results))
+		}
+		summary.Cities = append(summary.Cities, CityDestruction{City: city, Probability: probability})
+	}
+	sort.SliceStable(summary.Cities, func(i, j int) bool {
+		if summary.Cities[i].Probability != summary.Cities[j].Probability {
+			return summary.Cities[i].Probability > summary.Cities[j].Probability
+		}
+		return summary.Cities[i].City < summary.Cities[j].City
+	})
+
+	return summary
+}
+
+func newDistribution(values []float64) Distribution {
+	if len(values) == 0 {
+		return Distribution{}
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, v := range sorted {
+		sum += v
+	}
+	mean := sum / float64(len(sorted))
+
+	var variance float64
+	for _, v := range sorted {
+		variance += (v - mean) * (v - mean)
+	}
+	variance /= float64(len(sorted))
+
+	return Distribution{
+		Min:    sorted[0],
+		Max:    sorted[len(sorted)-1],
+		Mean:   mean,
+		StdDev: math.Sqrt(variance),
+		Median: percentile(sorted, 0.5),
+		P90:    percentile(sorted, 0.9),
+	}
+}
+
+// percentile returns the value below which the given fraction of the sorted values fall,
+// using the nearest-rank method.
+func percentile(sorted []float64, fraction float64) float64 {
+	rank := int(math.Ceil(fraction*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+
+	return sorted[rank]
+}
+
+// WriteTable writes the summary as human-readable tables.
+func (s Summary) WriteTable(w io.Writer) error {
+	fmt.Fprintf(w, "%d runs\n\n", s.Runs)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Metric\tMin\tMax\tMean\tStdDev\tMedian\tP90")
+
+	metrics := []struct {
+		name string
+		d    Distribution
+	}{
+		{"Turns", s.Turns},
+		{"Cities destroyed", s.CitiesDestroyed},
+		{"Aliens killed", s.Killed},
+		{"Aliens trapped", s.Trapped},
+		{"Survivors", s.Survivors},
+		{"Population lost", s.PopulationLost},
+	}
+	for _, m := range metrics {
+		fmt.Fprintf(tw, "%s\t%g\t%g\t%.2f\t%.2f\t%g\t%g\n", m.name, m.d.Min, m.d.Max, m.d.Mean, m.d.StdDev, m.d.Median, m.d.P90)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(tw, "Stop reason\tRuns")
+	reasons := make([]string, 0, len(s.Reasons))
+	for reason := range s.Reasons {
+		reasons = append(reasons, string(reason))
+	}
+	sort.Strings(reasons)
+	for _, reason := range reasons {
+		fmt.Fprintf(tw, "%s\t%d\n", reason, s.Reasons[alien.StopReason(reason)])
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(tw, "City\tDestroyed")
+	for _, c := range s.Cities {
+		fmt.Fprintf(tw, "%s\t%.1f%%\n", c.City, c.Probability*100)
+	}
+
+	return tw.Flush()
+}
+
+// WriteCSV writes the result of each simulation as a CSV row.
+func (s Summary) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"run", "seed", "turns", "cities_destroyed", "killed", "trapped", "survivors", "population_lost", "reason", "destroyed"}); err != nil {
+		return err
+	}
+
+	for i, r := range s.Results {
+		row := []string{
+			strconv.Itoa(i + 1),
+			strconv.FormatInt(r.Seed, 10),
+			strconv.Itoa(r.Turns),
+			strconv.Itoa(len(r.CitiesDestroyed)),
+			strconv.Itoa(r.Killed),
+			strconv.Itoa(r.Trapped),
+			strconv.Itoa(r.Survivors),
+			strconv.Itoa(r.PopulationLost),
+			string(r.Reason),
+			strings.Join(r.CitiesDestroyed, ";"),
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes the whole summary, including the result of each simulation, as JSON.
+func (s Summary) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(s)
+}
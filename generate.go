@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/santihernandezc/alien-invasion/world"
+)
+
+// Sources for the names of generated cities, besides a file with one name per line.
+const (
+	namesNumbered  = "numbered"
+	namesSyllables = "syllables"
+)
+
+// runGenerate writes a procedurally generated map.
+func runGenerate(args []string) int {
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+	topology := fs.String("topology", world.TopologyGrid, "shape of the road network: grid, ring, tree, random, geometric, scale-free or clusters")
+	cities := fs.Int("n", 25, "number of cities")
+	seed := fs.Int64("seed", 1, "seed for every random decision")
+	names := fs.String("names", namesNumbered, "names of the cities: numbered, syllables or the path to a file with one name per line")
+	degree := fs.Float64("degree", 3, "average number of roads per city, up to 4, for the random, geometric, scale-free and clusters topologies")
+	clusters := fs.Int("clusters", 3, "number of disconnected clusters, for the clusters topology")
+	format := fs.String("format", "json", "output format: json or text")
+	out := fs.String("out", "", "path to write the map to, stdout if empty")
+	parseFlags(fs, args)
+
+	// The map might go to stdout, so the rest goes to stderr
+	log := log.New(os.Stderr, "", 0)
+
+	nameSource, err := loadNames(*names)
+	if err != nil {
+		log.Printf("Error reading names: %v", err)
+		return exitError
+	}
+
+	w, err := world.Generate(world.GenerateOptions{
+		Topology: *topology,
+		Cities:   *cities,
+		Seed:     *seed,
+		Names:    nameSource,
+		Degree:   *degree,
+		Clusters: *clusters,
+	})
+	if err != nil {
+		log.Printf("Error generating map: %v", err)
+		return exitError
+	}
+
+	// Write the map before creating the file, so maps that can't be written don't leave one behind
+	var buf bytes.Buffer
+	switch *format {
+	case "json":
+		err = w.WriteJSON(&buf)
+	case "text":
+		err = w.WriteText(&buf)
+	default:
+		log.Printf("Invalid output format %q", *format)
+		return exitError
+	}
+	if err != nil {
+		log.Printf("Error writing map: %v", err)
+		return exitError
+	}
+
+	if *out == "" {
+		if _, err := buf.WriteTo(os.Stdout); err != nil {
+			log.Printf("Error writing map: %v", err)
+			return exitError
+		}
+		return exitOK
+	}
+	if err := os.WriteFile(*out, buf.Bytes(), 0o644); err != nil {
+		log.Printf("Error creating file in path %s: %v", *out, err)
+		return exitError
+	}
+	return exitOK
+}
+
+// loadNames returns the name source for the -names flag.
+func loadNames(names string) (world.NameSource, error) {
+	switch names {
+	case namesNumbered:
+		return world.NumberedNames("City"), nil
+	case namesSyllables:
+		return world.SyllableNames(), nil
+	}
+
+	f, err := os.Open(names)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var list []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if name := strings.TrimSpace(scanner.Text()); name != "" {
+			list = append(list, name)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("no names in %s", names)
+	}
+
+	return world.ListNames(list), nil
+}
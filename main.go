@@ -1,126 +1,428 @@
 package main
 
 import (
+	"bytes"
 	"flag"
+	"fmt"
+	"io"
 	"log"
 	"math/rand"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/santihernandezc/alien-invasion/alien"
+	"github.com/santihernandezc/alien-invasion/renderer"
+	"github.com/santihernandezc/alien-invasion/renderer/raylib"
 	"github.com/santihernandezc/alien-invasion/world"
+)
 
-	rl "github.com/gen2brain/raylib-go/raylib"
+// Exit codes returned by the simulation and the commands.
+const (
+	exitOK                 = 0
+	exitError              = 1
+	exitMovementsExhausted = 3
+	exitInvalidMap         = 4
+	exitTimeLimit          = 5
 )
 
+// commands maps subcommand names with the function running them.
+// Each command parses its own flags from the given arguments.
+var commands = map[string]func(args []string) int{
+	"validate": runValidate,
+	"replay":   runReplay,
+	"batch":    runBatch,
+	"generate": runGenerate,
+	"analyze":  runAnalyze,
+}
+
 var (
-	path     = flag.String("path", "config.json", "path to the json config file")
-	n        = flag.Int("n", 5, "number of aliens for the simulation")
-	directed = flag.Bool("directed", false, "use a directed graph")
+	path      = flag.String("path", "config.json", "path to the map file, in json or text format")
+	n         = flag.Int("n", 5, "number of aliens for the simulation")
+	directed  = flag.Bool("directed", false, "use a directed graph")
+	headless  = flag.Bool("headless", false, "run the simulation without opening a window")
+	seed      = flag.Int64("seed", 0, "seed for every random decision, a random one is used if 0")
+	logFormat = flag.String("log-format", logFormatText, "format of the event log: text or jsonl")
+	strategy  = flag.String("strategy", alien.StrategyRandomWalk, "comma-separated movement strategies, assigned to aliens in order")
+	layout    = flag.String("layout", world.LayoutForce, "how cities are placed in the window: force, grid or random")
+	dotBefore = flag.String("dot-before", "", "path to write the world to in DOT format before the simulation")
+	dotAfter  = flag.String("dot-after", "", "path to write the world to in DOT format after the simulation")
+	save      = flag.String("snapshot", "", "path to save the simulation to when it stops or the window is closed")
+	restore   = flag.String("restore", "", "path to a snapshot to resume instead of starting a new simulation")
+	history   = flag.Int("history", 1000, "number of steps that can be undone in the window")
+	rules     = addRulesFlags(flag.CommandLine)
+	stop      = addStopFlags(flag.CommandLine)
 )
 
-func init() {
-	flag.Parse()
-}
+// nopLogger discards everything, used when events are not logged as text.
+var nopLogger = log.New(io.Discard, "", 0)
+
+// Formats for the event log.
+const (
+	logFormatText  = "text"
+	logFormatJSONL = "jsonl"
+)
 
 func main() {
-	rand.Seed(time.Now().Unix())
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			os.Exit(cmd(os.Args[2:]))
+		}
+	}
+
+	parseFlags(flag.CommandLine, os.Args[1:])
 	log := log.New(os.Stdout, "", 0)
 
-	// Read and parse file into World map.
-	log.Printf("Initializing world from file %q", *path)
-	b, err := os.ReadFile(*path)
+	// Keep stdout for the events when they're meant to be parsed
+	switch *logFormat {
+	case logFormatText:
+	case logFormatJSONL:
+		log.SetOutput(os.Stderr)
+	default:
+		log.Fatalf("Invalid log format %q", *logFormat)
+	}
+
+	// Only the window keeps the steps to undo
+	if *headless && isSet("history") {
+		log.Fatalf("Flag -history can't be used with -headless, there's no window to undo steps in")
+	}
+
+	// Log the seed so the run can be reproduced
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	log.Printf("Using seed %d", *seed)
+
+	ao, err := newOrchestrator(log)
+	if err != nil {
+		log.Fatalf("Error initializing simulation: %v", err)
+	}
+	ao.World().SetRand(rand.New(rand.NewSource(*seed)))
+
+	var events *alien.JSONLSubscriber
+	if *logFormat == logFormatJSONL {
+		events = alien.NewJSONLSubscriber(os.Stdout)
+		ao.Subscribe(events)
+	}
+
+	code := exitOK
+	if *headless {
+		code = runHeadless(ao, log)
+	} else {
+		runWindow(ao, log)
+	}
+
+	// A broken event log is an error, even if the simulation ended well
+	if events != nil && events.Err() != nil {
+		log.Printf("Error writing events: %v", events.Err())
+		code = exitError
+	}
+	os.Exit(code)
+}
+
+// parseFlags parses the flags of a command, which doesn't take positional arguments.
+// Like any other invalid flag, positional arguments exit with the usage of the command.
+func parseFlags(fs *flag.FlagSet, args []string) {
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		fmt.Fprintf(fs.Output(), "unexpected argument %q, values are set with flags\n", fs.Arg(0))
+		fs.Usage()
+		os.Exit(2)
+	}
+}
+
+// addRulesFlags defines the flags for the combat rules in fs, starting from the default rules.
+func addRulesFlags(fs *flag.FlagSet) *alien.Rules {
+	rules := alien.DefaultRules()
+	fs.StringVar(&rules.TurnModel, "turn-model", rules.TurnModel, "how aliens take turns: sequential or simultaneous")
+	fs.BoolVar(&rules.CrossingFights, "crossing-fights", rules.CrossingFights, "aliens crossing each other on a road fight, with the simultaneous turn model")
+	fs.IntVar(&rules.Threshold, "threshold", rules.Threshold, "number of aliens in a city that starts a fight")
+	fs.BoolVar(&rules.ArrivingSurvives, "arriving-survives", rules.ArrivingSurvives, "the alien arriving to a city survives the fight")
+	fs.Var((*negatedBool)(&rules.DestroyCity), "keep-cities", "fights kill aliens but don't destroy the city")
+	fs.Float64Var(&rules.SurvivalProbability, "survival-probability", rules.SurvivalProbability, "probability of each alien surviving a fight")
+	fs.Float64Var(&rules.Speed, "speed", rules.Speed, "length of road aliens travel per turn, every road takes one turn if 0")
+	fs.IntVar(&rules.RoadsLost, "roads-lost", rules.RoadsLost, "amount of roads a city loses in every fight that doesn't destroy it")
+	fs.IntVar(&rules.BlockTurns, "block-turns", rules.BlockTurns, "amount of turns lost roads stay blocked, they're destroyed for good if 0")
+	fs.BoolVar(&rules.RetireIsolated, "retire-isolated", rules.RetireIsolated, "retire the aliens that can't meet any other alien anymore")
+
+	return &rules
+}
+
+// addStopFlags defines the flags for the conditions that stop the simulation in fs.
+func addStopFlags(fs *flag.FlagSet) *alien.StopConditions {
+	var stop alien.StopConditions
+	fs.IntVar(&stop.MaxTurns, "movements", 10000, "maximum number of turns, no limit if 0")
+	fs.BoolVar(&stop.AllCitiesDestroyed, "stop-destroyed", false, "stop when every city was destroyed")
+	fs.BoolVar(&stop.NoMobileAliens, "stop-immobile", false, "stop when no alien can move")
+	fs.BoolVar(&stop.AliensIsolated, "stop-isolated", false, "stop when no alien can meet another one")
+	fs.DurationVar(&stop.TimeLimit, "time-limit", 0, "maximum wall-clock duration, no limit if 0")
+
+	return &stop
+}
+
+// negatedBool is a boolean flag that sets the opposite value to the variable it points to.
+type negatedBool bool
+
+func (b *negatedBool) Set(s string) error {
+	v, err := strconv.ParseBool(s)
 	if err != nil {
-		log.Fatalf("Error opening file in path %s: %v", *path, err)
+		return err
+	}
+	*b = negatedBool(!v)
+	return nil
+}
+
+func (b *negatedBool) String() string {
+	if b == nil {
+		return "false"
 	}
+	return strconv.FormatBool(!bool(*b))
+}
 
-	worldMap, err := world.NewFromBytes(b, *directed, 800, 450)
+func (b *negatedBool) IsBoolFlag() bool { return true }
+
+// loadWorld reads the map in path, JSON files are expected to have a .json
+// extension and any other file is parsed using the text map format.
+func loadWorld(path string, isDirected bool) (*world.World, error) {
+	b, err := os.ReadFile(path)
 	if err != nil {
-		log.Fatalf("Error reading and parsing file: %v", err)
-	}
-
-	// Init window
-	rl.InitWindow(800, 450, "Alien Invasion")
-	rl.SetTargetFPS(60)
-
-	// Load textures
-	alienImg := rl.LoadImage("./assets/alien.png")
-	alienTexture := rl.LoadTextureFromImage(alienImg)
-	alienTexture.Width = int32(float32(alienTexture.Width) * 0.2)
-	alienTexture.Height = int32(float32(alienTexture.Height) * 0.2)
-	rl.UnloadImage(alienImg)
-	explosionImg := rl.LoadImage("./assets/explosion.png")
-	explosionTexture := rl.LoadTextureFromImage(explosionImg)
-	explosionTexture.Width = int32(float32(explosionTexture.Width) * 0.1)
-	explosionTexture.Height = int32(float32(explosionTexture.Height) * 0.1)
-	rl.UnloadImage(explosionImg)
-
-	// Instantiate aliens and seed randomness
-	log.Printf("Initializing %d aliens", *n)
-	rngSeed := time.Now().UnixNano()
-	ao, err := alien.NewOrchestrator(*n, rngSeed, worldMap, alienTexture, log)
+		return nil, fmt.Errorf("error opening file in path %s: %w", path, err)
+	}
+
+	return parseWorld(path, b, isDirected)
+}
+
+// parseWorld parses the contents of the map file in path, in the format loadWorld expects for it.
+func parseWorld(path string, b []byte, isDirected bool) (*world.World, error) {
+	if world.FormatFromPath(path) == world.FormatJSON {
+		return world.NewFromBytes(b, isDirected)
+	}
+
+	return world.NewFromReader(bytes.NewReader(b), isDirected)
+}
+
+// printWorld prints a World in the text map format, or as a JSON map if it has roads without
+// a direction, which the text format can't represent, so it can be loaded again either way.
+func printWorld(w *world.World, out io.Writer) error {
+	// WriteText checks the directions before writing anything
+	if err := w.WriteText(out); err != nil {
+		return w.WriteJSON(out)
+	}
+	return nil
+}
+
+// newOrchestrator creates the world and the aliens, or restores them from a snapshot,
+// logging events in text unless they're written as JSON Lines.
+func newOrchestrator(log *log.Logger) (*alien.AlienOrchestrator, error) {
+	eventLog := log
+	if *logFormat == logFormatJSONL {
+		eventLog = nopLogger
+	}
+
+	var ao *alien.AlienOrchestrator
+	if *restore != "" {
+		if ignored := restoredFlags(); len(ignored) > 0 {
+			return nil, fmt.Errorf("flags %s can't be used with -restore, the snapshot already has them", strings.Join(ignored, ", "))
+		}
+
+		log.Printf("Restoring simulation from file %q", *restore)
+		f, err := os.Open(*restore)
+		if err != nil {
+			return nil, fmt.Errorf("error opening file in path %s: %w", *restore, err)
+		}
+		defer f.Close()
+
+		if ao, err = alien.Restore(f, eventLog); err != nil {
+			return nil, err
+		}
+	} else {
+		// Read and parse file into World map.
+		log.Printf("Initializing world from file %q", *path)
+		worldMap, err := loadWorld(*path, *directed)
+		if err != nil {
+			return nil, fmt.Errorf("error reading and parsing file: %w", err)
+		}
+
+		log.Printf("Initializing %d aliens", *n)
+		if ao, err = alien.NewOrchestrator(*n, *seed, worldMap, *rules, eventLog); err != nil {
+			return nil, err
+		}
+		if err := alien.AssignStrategies(ao.Aliens, strings.Split(*strategy, ",")); err != nil {
+			return nil, err
+		}
+	}
+
+	return ao, nil
+}
+
+// restoredFlags returns the flags set in the command line that a snapshot already has,
+// which would be ignored when restoring it.
+func restoredFlags() []string {
+	restored := map[string]bool{"path": true, "n": true, "directed": true, "seed": true, "strategy": true}
+	rulesFlags := flag.NewFlagSet("rules", flag.ContinueOnError)
+	addRulesFlags(rulesFlags)
+	rulesFlags.VisitAll(func(f *flag.Flag) {
+		restored[f.Name] = true
+	})
+
+	var set []string
+	flag.Visit(func(f *flag.Flag) {
+		if restored[f.Name] {
+			set = append(set, "-"+f.Name)
+		}
+	})
+	return set
+}
+
+// isSet reports whether a flag was set in the command line.
+func isSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// runHeadless runs the whole simulation without a display and returns the exit code.
+// The exit code tells whether the simulation stopped by itself or because of a limit.
+func runHeadless(ao *alien.AlienOrchestrator, log *log.Logger) int {
+	if err := writeDOT(*dotBefore, ao); err != nil {
+		log.Printf("Error writing DOT file: %v", err)
+		return exitError
+	}
+
+	result := ao.Run(*stop)
+
+	if err := writeDOT(*dotAfter, ao); err != nil {
+		log.Printf("Error writing DOT file: %v", err)
+		return exitError
+	}
+	if err := writeSnapshot(*save, ao); err != nil {
+		log.Printf("Error writing snapshot: %v", err)
+		return exitError
+	}
+
+	logLosses(result.Losses, log)
+
+	// Print what's left of the world
+	if err := printWorld(result.World, log.Writer()); err != nil {
+		log.Printf("Error writing world: %v", err)
+		return exitError
+	}
+
+	switch result.Reason {
+	case alien.StopMaxTurns:
+		return exitMovementsExhausted
+	case alien.StopTimeLimit:
+		return exitTimeLimit
+	}
+	return exitOK
+}
+
+func runWindow(ao *alien.AlienOrchestrator, log *log.Logger) {
+	worldMap := ao.World()
+	l, err := world.NewLayout(*layout)
 	if err != nil {
-		log.Fatalf("error creating aliens: %v", err)
+		log.Fatalf("Error placing cities: %v", err)
 	}
+	l.Place(worldMap, 800, 450)
 
-	var counter int
+	r := raylib.New(800, 450, "Alien Invasion", "assets")
+	defer r.Close()
 
-	stepSignal := make(chan bool)
-	go tick(stepSignal)
-	go listener(stepSignal)
+	if err := writeDOT(*dotBefore, ao); err != nil {
+		log.Fatalf("Error writing DOT file: %v", err)
+	}
+
+	ao.KeepHistory(*history)
+	p := newPlayback(time.Second)
 
 	// Draw
-	for !rl.WindowShouldClose() {
-		rl.BeginDrawing()
-		rl.ClearBackground(rl.RayWhite)
-		drawMap(worldMap, explosionTexture)
+	for !r.ShouldClose() {
+		r.SetStatus(fmt.Sprintf("Turn %d, %d aliens left", ao.Turn(), len(ao.Aliens)))
+		r.Draw(worldMap, ao.Aliens)
+
+		// Going back in time pauses the simulation, so it can be inspected
+		action := r.PollAction()
+		switch action {
+		case renderer.ActionStepBack:
+			p.paused = true
+			ao.StepBack()
+			continue
+		case renderer.ActionJump:
+			p.paused = true
+			ao.JumpToTurn(r.JumpTarget(), *stop)
+			continue
+		}
 
-		for _, alien := range ao.Aliens {
-			alien.Draw()
+		if !p.next(action) {
+			continue
 		}
-		rl.EndDrawing()
-
-		select {
-		case <-stepSignal:
-			if len(ao.Aliens) > 0 {
-				ao.Step(ao.Aliens[counter%len(ao.Aliens)])
-				counter++
-			}
-		default:
+
+		// Move one alien at a time, producing the same events as a headless run
+		if reason := ao.Stopped(*stop); reason != "" {
+			ao.End(reason)
 			continue
 		}
+		ao.StepAlien()
 	}
 
-	rl.UnloadTexture(alienTexture)
-	rl.CloseWindow()
+	// Write the world as it was when the window was closed
+	if err := writeDOT(*dotAfter, ao); err != nil {
+		log.Fatalf("Error writing DOT file: %v", err)
+	}
+	if err := writeSnapshot(*save, ao); err != nil {
+		log.Fatalf("Error writing snapshot: %v", err)
+	}
+	logLosses(ao.Losses(), log)
 }
 
-func drawMap(worldMap *world.World, explosionTexture rl.Texture2D) {
-	for _, city := range worldMap.DestroyedCities {
-		rl.DrawText(city.Name, int32(city.Position.X)+10, int32(city.Position.Y)+10, 10, rl.Black)
-		rl.DrawTexture(explosionTexture, int32(city.Position.X)-10, int32(city.Position.Y)-10, rl.White)
+// logLosses logs the population and strategic value lost by each city, if the map has any.
+func logLosses(losses alien.Losses, log *log.Logger) {
+	if losses.TotalPopulation == 0 && losses.TotalValue == 0 {
+		return
 	}
 
-	for _, city := range worldMap.Cities {
-		rl.DrawText(city.Name, int32(city.Position.X)+10, int32(city.Position.Y)+10, 10, rl.Black)
-		rl.DrawCircleLines(int32(city.Position.X), int32(city.Position.Y), 10, rl.Black)
-		for _, neighbor := range city.Neighbors {
-			rl.DrawLine(int32(city.Position.X), int32(city.Position.Y), int32(neighbor.Position.X), int32(neighbor.Position.Y), rl.Gray)
+	log.Printf("Population lost: %d of %d", losses.Population, losses.TotalPopulation)
+	log.Printf("Strategic value lost: %.2f of %.2f", losses.Value, losses.TotalValue)
+	for _, c := range losses.Cities {
+		state := fmt.Sprintf("%d hit points lost", c.Damage)
+		if c.Destroyed {
+			state = "destroyed"
 		}
+		log.Printf("  %s: %s, %d people and %.2f value lost", c.City, state, c.Population, c.Value)
 	}
 }
 
-func listener(c chan bool) {
-	for {
-		if rl.IsKeyReleased(32) {
-			c <- true
-		}
+// writeDOT writes the world with the aliens in it to path in DOT format, if path is set.
+func writeDOT(path string, ao *alien.AlienOrchestrator) error {
+	if path == "" {
+		return nil
 	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating file in path %s: %w", path, err)
+	}
+	defer f.Close()
+
+	return ao.World().WriteDOT(f, world.DOTOptions{Aliens: ao.AliensByCity()})
 }
 
-func tick(c chan bool) {
-	for {
-		c <- true
-		time.Sleep(1 * time.Second)
+// writeSnapshot saves the simulation to path, if path is set.
+func writeSnapshot(path string, ao *alien.AlienOrchestrator) error {
+	if path == "" {
+		return nil
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating file in path %s: %w", path, err)
 	}
+	defer f.Close()
+
+	return ao.Snapshot(f)
 }
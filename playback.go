@@ -0,0 +1,55 @@
+package main
+
+import (
+	"time"
+
+	"github.com/santihernandezc/alien-invasion/renderer"
+)
+
+// Limits for the time between steps in the window.
+const (
+	minStepInterval = time.Second / 16
+	maxStepInterval = 8 * time.Second
+)
+
+// playback controls the pace of the simulation in the window.
+type playback struct {
+	interval time.Duration
+	paused   bool
+	lastStep time.Time
+}
+
+func newPlayback(interval time.Duration) *playback {
+	return &playback{
+		interval: interval,
+		lastStep: time.Now(),
+	}
+}
+
+// next handles the action requested by the user and reports whether
+// the simulation should move forward.
+func (p *playback) next(action renderer.Action) bool {
+	switch action {
+	case renderer.ActionStep:
+		// Stepping manually works even when paused
+		p.lastStep = time.Now()
+		return true
+	case renderer.ActionPause:
+		p.paused = !p.paused
+	case renderer.ActionFaster:
+		if p.interval/2 >= minStepInterval {
+			p.interval /= 2
+		}
+	case renderer.ActionSlower:
+		if p.interval*2 <= maxStepInterval {
+			p.interval *= 2
+		}
+	}
+
+	if p.paused || time.Since(p.lastStep) < p.interval {
+		return false
+	}
+	p.lastStep = time.Now()
+
+	return true
+}
@@ -0,0 +1,184 @@
+// Package raylib implements a renderer.Renderer that draws the simulation in a window using raylib.
+package raylib
+
+import (
+	"path/filepath"
+	"strconv"
+
+	rl "github.com/gen2brain/raylib-go/raylib"
+	"github.com/santihernandezc/alien-invasion/alien"
+	"github.com/santihernandezc/alien-invasion/renderer"
+	"github.com/santihernandezc/alien-invasion/world"
+)
+
+// Renderer draws the simulation in a raylib window.
+type Renderer struct {
+	alienTexture     rl.Texture2D
+	explosionTexture rl.Texture2D
+
+	// positions keeps the on-screen position of each alien by ID,
+	// used to animate aliens moving from one city to another.
+	positions map[int]rl.Vector2
+
+	status string
+	// typed are the digits of the turn to jump to typed so far, and target the last turn requested
+	typed  string
+	target int
+}
+
+// maxTurnDigits is the maximum length of the turn to jump to.
+const maxTurnDigits = 9
+
+var _ renderer.Renderer = (*Renderer)(nil)
+
+// New opens a window with the given dimensions and loads the textures from the assets directory.
+func New(width, height int32, title, assetsDir string) *Renderer {
+	// Init window
+	rl.InitWindow(width, height, title)
+	rl.SetTargetFPS(60)
+
+	return &Renderer{
+		alienTexture:     loadTexture(filepath.Join(assetsDir, "alien.png"), 0.2),
+		explosionTexture: loadTexture(filepath.Join(assetsDir, "explosion.png"), 0.1),
+		positions:        make(map[int]rl.Vector2),
+	}
+}
+
+func loadTexture(path string, scale float32) rl.Texture2D {
+	img := rl.LoadImage(path)
+	texture := rl.LoadTextureFromImage(img)
+	texture.Width = int32(float32(texture.Width) * scale)
+	texture.Height = int32(float32(texture.Height) * scale)
+	rl.UnloadImage(img)
+
+	return texture
+}
+
+// Draw renders the world and the aliens in it.
+func (r *Renderer) Draw(w *world.World, aliens []*alien.Alien) {
+	rl.BeginDrawing()
+	rl.ClearBackground(rl.RayWhite)
+	r.drawMap(w)
+
+	for _, a := range aliens {
+		r.drawAlien(a)
+	}
+
+	status := r.status
+	if r.typed != "" {
+		status += "  Go to turn: " + r.typed
+	}
+	rl.DrawText(status, 10, 10, 10, rl.DarkGray)
+	rl.EndDrawing()
+}
+
+// keyActions maps keys with the action they trigger.
+var keyActions = []struct {
+	key    int32
+	action renderer.Action
+}{
+	{rl.KeySpace, renderer.ActionStep},
+	{rl.KeyRight, renderer.ActionStep},
+	{rl.KeyLeft, renderer.ActionStepBack},
+	{rl.KeyP, renderer.ActionPause},
+	{rl.KeyUp, renderer.ActionFaster},
+	{rl.KeyDown, renderer.ActionSlower},
+}
+
+// PollAction returns the action for the first key released since the last frame:
+// space or right arrow to step, left arrow to step back, P to pause and up/down arrows
+// to change the speed. Typing a number and pressing enter jumps to that turn.
+func (r *Renderer) PollAction() renderer.Action {
+	for c := rl.GetCharPressed(); c > 0; c = rl.GetCharPressed() {
+		if c >= '0' && c <= '9' && len(r.typed) < maxTurnDigits {
+			r.typed += string(rune(c))
+		}
+	}
+	if rl.IsKeyPressed(rl.KeyBackspace) && r.typed != "" {
+		r.typed = r.typed[:len(r.typed)-1]
+	}
+	if rl.IsKeyReleased(rl.KeyEnter) && r.typed != "" {
+		r.target, _ = strconv.Atoi(r.typed)
+		r.typed = ""
+		return renderer.ActionJump
+	}
+
+	for _, ka := range keyActions {
+		if rl.IsKeyReleased(ka.key) {
+			return ka.action
+		}
+	}
+
+	return renderer.ActionNone
+}
+
+// JumpTarget returns the last turn typed before pressing enter.
+func (r *Renderer) JumpTarget() int {
+	return r.target
+}
+
+// SetStatus sets the text drawn in the top left corner of the window.
+func (r *Renderer) SetStatus(status string) {
+	r.status = status
+}
+
+// ShouldClose reports whether the window was closed.
+func (r *Renderer) ShouldClose() bool {
+	return rl.WindowShouldClose()
+}
+
+// Close unloads the textures and closes the window.
+func (r *Renderer) Close() {
+	rl.UnloadTexture(r.alienTexture)
+	rl.UnloadTexture(r.explosionTexture)
+	rl.CloseWindow()
+}
+
+func (r *Renderer) drawMap(w *world.World) {
+	for _, city := range w.DestroyedCities {
+		rl.DrawText(city.Name, int32(city.Position.X)+10, int32(city.Position.Y)+10, 10, rl.Black)
+		rl.DrawTexture(r.explosionTexture, int32(city.Position.X)-10, int32(city.Position.Y)-10, rl.White)
+	}
+
+	for _, city := range w.Cities {
+		rl.DrawText(city.Name, int32(city.Position.X)+10, int32(city.Position.Y)+10, 10, rl.Black)
+		rl.DrawCircleLines(int32(city.Position.X), int32(city.Position.Y), 10, rl.Black)
+		for _, neighbor := range city.Neighbors {
+			rl.DrawLine(int32(city.Position.X), int32(city.Position.Y), int32(neighbor.Position.X), int32(neighbor.Position.Y), rl.Gray)
+		}
+	}
+
+	// Blocked roads are drawn fainter, since they'll be back
+	for _, b := range w.BlockedRoads() {
+		rl.DrawLine(int32(b.From.Position.X), int32(b.From.Position.Y), int32(b.To.Position.X), int32(b.To.Position.Y), rl.LightGray)
+	}
+}
+
+func (r *Renderer) drawAlien(a *alien.Alien) {
+	next := rl.NewVector2(a.Position.Position.X, a.Position.Position.Y)
+	if a.Traveling() {
+		// Aliens on a road are drawn as far along it as they traveled
+		dest := rl.NewVector2(a.Destination.Position.X, a.Destination.Position.Y)
+		next = rl.Vector2Lerp(next, dest, a.Progress())
+	}
+
+	// Aliens we haven't seen yet start in their current city
+	pos, ok := r.positions[a.ID]
+	if !ok {
+		pos = next
+	}
+
+	// Modify position if needed
+	distance := rl.Vector2Distance(pos, next)
+	if distance != 0 {
+		if distance < 0.1 {
+			pos = next
+		} else {
+			pos = rl.Vector2Add(pos, rl.Vector2Scale(rl.Vector2Subtract(next, pos), 0.1))
+		}
+	}
+	r.positions[a.ID] = pos
+
+	// Draw
+	rl.DrawTexture(r.alienTexture, int32(pos.X)-r.alienTexture.Width/2, int32(pos.Y)-r.alienTexture.Height/2, rl.White)
+}
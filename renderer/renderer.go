@@ -0,0 +1,45 @@
+// Package renderer defines how a simulation is presented to the user.
+// The simulation core (world and alien packages) doesn't depend on any
+// graphics library, implementations of Renderer live in their own packages.
+package renderer
+
+import (
+	"github.com/santihernandezc/alien-invasion/alien"
+	"github.com/santihernandezc/alien-invasion/world"
+)
+
+// Action is something the user asked for while the simulation is being rendered.
+type Action int
+
+const (
+	// ActionNone means the user didn't ask for anything.
+	ActionNone Action = iota
+	// ActionStep means the user wants the simulation to move forward.
+	ActionStep
+	// ActionPause means the user wants to pause the simulation, or resume it if it's paused.
+	ActionPause
+	// ActionFaster means the user wants the simulation to move forward faster.
+	ActionFaster
+	// ActionSlower means the user wants the simulation to move forward slower.
+	ActionSlower
+	// ActionStepBack means the user wants to undo the last step of the simulation.
+	ActionStepBack
+	// ActionJump means the user wants to go to the turn returned by JumpTarget.
+	ActionJump
+)
+
+// Renderer draws the state of a simulation and collects user input.
+type Renderer interface {
+	// Draw renders a single frame with the world and the aliens in it.
+	Draw(w *world.World, aliens []*alien.Alien)
+	// PollAction returns the action requested by the user since the last call.
+	PollAction() Action
+	// JumpTarget returns the turn requested by the user with the last ActionJump.
+	JumpTarget() int
+	// SetStatus sets a line of text describing the simulation, drawn in the next frames.
+	SetStatus(status string)
+	// ShouldClose reports whether the user wants to stop rendering.
+	ShouldClose() bool
+	// Close releases all the resources used by the renderer.
+	Close()
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"math/rand"
+	"os"
+	"time"
+
+	"github.com/santihernandezc/alien-invasion/alien"
+	"github.com/santihernandezc/alien-invasion/renderer/raylib"
+	"github.com/santihernandezc/alien-invasion/world"
+)
+
+// runReplay plays the events recorded in a JSON Lines event log on a map,
+// either in a window or printing them to stdout.
+func runReplay(args []string) int {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	path := fs.String("path", "config.json", "path to the map file, in json or text format")
+	directed := fs.Bool("directed", false, "use a directed graph")
+	eventsPath := fs.String("events", "events.jsonl", "path to the event log, as written with -log-format=jsonl")
+	headless := fs.Bool("headless", false, "print the replayed events instead of opening a window")
+	seed := fs.Int64("seed", 0, "seed of the recorded run, to place the cities in the same positions")
+	layout := fs.String("layout", world.LayoutForce, "how cities are placed in the window: force, grid or random")
+	parseFlags(fs, args)
+
+	log := log.New(os.Stdout, "", 0)
+
+	worldMap, err := loadWorld(*path, *directed)
+	if err != nil {
+		log.Printf("Error reading and parsing file: %v", err)
+		return exitError
+	}
+	if *seed != 0 {
+		worldMap.SetRand(rand.New(rand.NewSource(*seed)))
+	}
+
+	f, err := os.Open(*eventsPath)
+	if err != nil {
+		log.Printf("Error opening file in path %s: %v", *eventsPath, err)
+		return exitError
+	}
+	defer f.Close()
+
+	events, err := alien.ReadEvents(f)
+	if err != nil {
+		log.Printf("Error reading event log: %v", err)
+		return exitError
+	}
+
+	replay, err := alien.NewReplay(worldMap, events)
+	if err != nil {
+		log.Printf("Error creating replay: %v", err)
+		return exitError
+	}
+
+	if *headless {
+		for !replay.Done() {
+			if err := stepReplay(replay, log); err != nil {
+				return exitError
+			}
+		}
+
+		// Print what's left of the world
+		if err := printWorld(worldMap, log.Writer()); err != nil {
+			log.Printf("Error writing world: %v", err)
+			return exitError
+		}
+		return exitOK
+	}
+
+	l, err := world.NewLayout(*layout)
+	if err != nil {
+		log.Printf("Error placing cities: %v", err)
+		return exitError
+	}
+	l.Place(worldMap, 800, 450)
+
+	r := raylib.New(800, 450, "Alien Invasion (replay)", "assets")
+	defer r.Close()
+
+	p := newPlayback(time.Second)
+	for !r.ShouldClose() {
+		r.Draw(worldMap, replay.Aliens)
+
+		if p.next(r.PollAction()) && !replay.Done() {
+			if err := stepReplay(replay, log); err != nil {
+				return exitError
+			}
+		}
+	}
+
+	return exitOK
+}
+
+// stepReplay applies the next events of the replay and logs them.
+func stepReplay(replay *alien.Replay, log *log.Logger) error {
+	applied, err := replay.Step()
+	for _, e := range applied {
+		log.Print(e)
+	}
+
+	if err != nil {
+		log.Printf("Error replaying events: %v", err)
+	}
+	return err
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/santihernandezc/alien-invasion/world"
+)
+
+// runValidate reports every structural problem found in a map.
+// It returns exitInvalidMap if at least one of them is an error.
+func runValidate(args []string) int {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	path := fs.String("path", "config.json", "path to the map file, in json or text format")
+	directed := fs.Bool("directed", false, "use a directed graph")
+	parseFlags(fs, args)
+
+	log := log.New(os.Stdout, "", 0)
+
+	f, err := os.Open(*path)
+	if err != nil {
+		log.Printf("Error opening file in path %s: %v", *path, err)
+		return exitError
+	}
+	defer f.Close()
+
+	issues, err := world.Validate(f, world.FormatFromPath(*path), *directed)
+	if err != nil {
+		log.Printf("Error validating map: %v", err)
+		return exitError
+	}
+
+	var errors, warnings int
+	for _, issue := range issues {
+		log.Print(issue)
+		if issue.Severity == world.SeverityError {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+	log.Printf("%s: %d errors, %d warnings", *path, errors, warnings)
+
+	if errors > 0 {
+		return exitInvalidMap
+	}
+	return exitOK
+}
@@ -0,0 +1,72 @@
+package analysis
+
+import "github.com/santihernandezc/alien-invasion/world"
+
+// DegreeDistribution maps each degree with the amount of cities with that degree,
+// which is the amount of cities a city has roads to.
+func DegreeDistribution(w *world.World) map[int]int {
+	g := newGraph(w)
+	distribution := make(map[int]int)
+	for _, out := range g.out {
+		distribution[len(out)]++
+	}
+
+	return distribution
+}
+
+// Betweenness returns the betweenness centrality of every city: the sum, over every pair of
+// other cities, of the share of the shortest paths between them that go through the city.
+// Cities on many shortest paths are the ones aliens wandering around go through the most.
+// In undirected Worlds each pair of cities is counted once, regardless of the direction.
+func Betweenness(w *world.World) map[*world.City]float64 {
+	g := newGraph(w)
+	centrality := make([]float64, len(g.cities))
+
+	// Brandes' algorithm, with a breadth-first search from each city
+	for s := range g.cities {
+		var visited []int
+		preds := make([][]int, len(g.cities))
+		paths := make([]float64, len(g.cities))
+		dist := make([]int, len(g.cities))
+		for i := range dist {
+			dist[i] = -1
+		}
+
+		paths[s], dist[s] = 1, 0
+		for queue := []int{s}; len(queue) > 0; queue = queue[1:] {
+			i := queue[0]
+			visited = append(visited, i)
+			for _, j := range g.out[i] {
+				if dist[j] < 0 {
+					dist[j] = dist[i] + 1
+					queue = append(queue, j)
+				}
+				if dist[j] == dist[i]+1 {
+					paths[j] += paths[i]
+					preds[j] = append(preds[j], i)
+				}
+			}
+		}
+
+		// Add up the dependency of s on each city, starting from the farthest ones
+		dependency := make([]float64, len(g.cities))
+		for k := len(visited) - 1; k > 0; k-- {
+			j := visited[k]
+			for _, i := range preds[j] {
+				dependency[i] += paths[i] / paths[j] * (1 + dependency[j])
+			}
+			centrality[j] += dependency[j]
+		}
+	}
+
+	result := make(map[*world.City]float64, len(g.cities))
+	for i, city := range g.cities {
+		if !g.directed {
+			// Every path was counted from both of its ends
+			centrality[i] /= 2
+		}
+		result[city] = centrality[i]
+	}
+
+	return result
+}
@@ -0,0 +1,77 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDegreeDistribution(t *testing.T) {
+	tests := []struct {
+		name     string
+		worldDef string
+		directed bool
+		expected map[int]int
+	}{
+		{
+			"undirected world",
+			undirectedDef,
+			false,
+			map[int]int{1: 3, 2: 2, 3: 1},
+		},
+		{
+			"directed world, roads leaving each city",
+			directedDef,
+			true,
+			map[int]int{0: 1, 1: 2, 2: 1},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			w := newWorld(tt, test.worldDef, test.directed)
+			assert.Equal(tt, test.expected, DegreeDistribution(w))
+		})
+	}
+}
+
+func TestBetweenness(t *testing.T) {
+	tests := []struct {
+		name     string
+		worldDef string
+		directed bool
+		expected map[string]float64
+	}{
+		{
+			"undirected world",
+			undirectedDef,
+			false,
+			map[string]float64{"A": 0, "B": 2, "C": 0, "D": 0, "E": 0, "F": 0},
+		},
+		{
+			"shortest paths split between cities",
+			"A east=B\nB south=C\nC west=D\nD north=A\nA west=E",
+			false,
+			// Opposite cities of the cycle have two shortest paths, one through each of the other two
+			map[string]float64{"A": 3.5, "B": 1, "C": 0.5, "D": 1, "E": 0},
+		},
+		{
+			"directed world",
+			directedDef,
+			true,
+			map[string]float64{"A": 1, "B": 2, "C": 3, "D": 0},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			w := newWorld(tt, test.worldDef, test.directed)
+
+			betweenness := make(map[string]float64)
+			for city, b := range Betweenness(w) {
+				betweenness[city.Name] = b
+			}
+			assert.InDeltaMapValues(tt, test.expected, betweenness, 1e-9)
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package analysis
+
+import (
+	"sort"
+
+	"github.com/santihernandezc/alien-invasion/world"
+)
+
+// WeakComponents returns the groups of cities connected by roads, taking roads both ways
+// even in directed Worlds. Cities in each component are sorted by name, and components
+// are sorted by their first city.
+func WeakComponents(w *world.World) [][]*world.City {
+	g := newGraph(w)
+	adjacent := g.undirected(g.edges())
+
+	var components [][]*world.City
+	visited := make([]bool, len(g.cities))
+	for start := range g.cities {
+		if visited[start] {
+			continue
+		}
+
+		visited[start] = true
+		component := []int{start}
+		for queue := []int{start}; len(queue) > 0; queue = queue[1:] {
+			for _, e := range adjacent[queue[0]] {
+				if !visited[e.to] {
+					visited[e.to] = true
+					component = append(component, e.to)
+					queue = append(queue, e.to)
+				}
+			}
+		}
+		components = append(components, g.sorted(component))
+	}
+
+	return components
+}
+
+// StrongComponents returns the groups of cities where every city can reach the rest following
+// the direction of the roads, which are the same as the weak components in undirected Worlds.
+// Cities in each component are sorted by name, and components are sorted by their first city.
+func StrongComponents(w *world.World) [][]*world.City {
+	return newGraph(w).strongComponents()
+}
+
+// StrongComponentsWith returns the strong components of a World as if it also had the given
+// roads between its cities, such as the blocked roads that will open again.
+func StrongComponentsWith(w *world.World, roads []world.Road) [][]*world.City {
+	return newGraph(w, roads...).strongComponents()
+}
+
+// strongComponents finds the strong components of the graph with Tarjan's algorithm.
+func (g *graph) strongComponents() [][]*world.City {
+	index := make([]int, len(g.cities))
+	low := make([]int, len(g.cities))
+	onStack := make([]bool, len(g.cities))
+	var stack []int
+	var components [][]int
+	next := 1
+
+	var visit func(i int)
+	visit = func(i int) {
+		index[i], low[i] = next, next
+		next++
+		stack = append(stack, i)
+		onStack[i] = true
+
+		for _, j := range g.out[i] {
+			if index[j] == 0 {
+				visit(j)
+				if low[j] < low[i] {
+					low[i] = low[j]
+				}
+			} else if onStack[j] && index[j] < low[i] {
+				low[i] = index[j]
+			}
+		}
+
+		// i is the root of a component, made of every city above it in the stack
+		if low[i] == index[i] {
+			var component []int
+			for {
+				j := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[j] = false
+				component = append(component, j)
+				if j == i {
+					break
+				}
+			}
+			components = append(components, component)
+		}
+	}
+
+	for i := range g.cities {
+		if index[i] == 0 {
+			visit(i)
+		}
+	}
+
+	// Components are found in reverse topological order, sort them like the weak ones
+	for _, component := range components {
+		sort.Ints(component)
+	}
+	sort.Slice(components, func(i, j int) bool { return components[i][0] < components[j][0] })
+
+	result := make([][]*world.City, len(components))
+	for i, component := range components {
+		result[i] = g.citiesAt(component)
+	}
+	return result
+}
+
+// sorted returns the cities with the given indexes, sorted by name.
+func (g *graph) sorted(indexes []int) []*world.City {
+	sort.Ints(indexes)
+	return g.citiesAt(indexes)
+}
@@ -0,0 +1,179 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/santihernandezc/alien-invasion/world"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	// undirectedDef has a cycle between B, C and D, with A hanging from B, and E and F apart
+	undirectedDef = "A east=B\nB east=C\nC north=D\nD north=B\nE west=F"
+	// directedDef has a cycle between A, B and C, which leads to D
+	directedDef = "A east=B\nB east=C\nC north=A\nC east=D"
+)
+
+func TestWeakComponents(t *testing.T) {
+	tests := []struct {
+		name      string
+		worldDef  string
+		directed  bool
+		destroyed []string
+		expected  [][]string
+	}{
+		{
+			"undirected world",
+			undirectedDef,
+			false,
+			nil,
+			[][]string{{"A", "B", "C", "D"}, {"E", "F"}},
+		},
+		{
+			"destroyed cities split components",
+			undirectedDef,
+			false,
+			[]string{"B"},
+			[][]string{{"A"}, {"C", "D"}, {"E", "F"}},
+		},
+		{
+			"directed world, roads taken both ways",
+			directedDef,
+			true,
+			nil,
+			[][]string{{"A", "B", "C", "D"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			w := newWorld(tt, test.worldDef, test.directed, test.destroyed...)
+			assert.Equal(tt, test.expected, componentNames(WeakComponents(w)))
+		})
+	}
+}
+
+func TestStrongComponents(t *testing.T) {
+	tests := []struct {
+		name     string
+		worldDef string
+		directed bool
+		expected [][]string
+	}{
+		{
+			"undirected world",
+			undirectedDef,
+			false,
+			[][]string{{"A", "B", "C", "D"}, {"E", "F"}},
+		},
+		{
+			"directed world",
+			directedDef,
+			true,
+			[][]string{{"A", "B", "C"}, {"D"}},
+		},
+		{
+			"directed world, road back",
+			directedDef + "\nD west=C",
+			true,
+			[][]string{{"A", "B", "C", "D"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			w := newWorld(tt, test.worldDef, test.directed)
+			assert.Equal(tt, test.expected, componentNames(StrongComponents(w)))
+		})
+	}
+}
+
+func TestStrongComponentsWith(t *testing.T) {
+	tests := []struct {
+		name      string
+		worldDef  string
+		directed  bool
+		destroyed []string
+		roads     [][2]string
+		expected  [][]string
+	}{
+		{
+			"undirected world, roads taken both ways",
+			undirectedDef,
+			false,
+			nil,
+			[][2]string{{"F", "A"}},
+			[][]string{{"A", "B", "C", "D", "E", "F"}},
+		},
+		{
+			"directed world",
+			directedDef,
+			true,
+			nil,
+			[][2]string{{"D", "A"}},
+			[][]string{{"A", "B", "C", "D"}},
+		},
+		{
+			"directed world, roads taken one way",
+			directedDef,
+			true,
+			nil,
+			[][2]string{{"A", "D"}},
+			[][]string{{"A", "B", "C"}, {"D"}},
+		},
+		{
+			"roads to destroyed cities are left out",
+			undirectedDef,
+			false,
+			[]string{"F"},
+			[][2]string{{"A", "F"}},
+			[][]string{{"A", "B", "C", "D"}, {"E"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			// Roads are taken before destroying the cities, like blocked roads whose cities were destroyed later
+			w := newWorld(tt, test.worldDef, test.directed)
+			var roads []world.Road
+			for _, r := range test.roads {
+				roads = append(roads, world.Road{From: w.Cities[r[0]], To: w.Cities[r[1]]})
+			}
+			for _, name := range test.destroyed {
+				w.DestroyCity(w.Cities[name])
+			}
+
+			assert.Equal(tt, test.expected, componentNames(StrongComponentsWith(w, roads)))
+		})
+	}
+}
+
+// newWorld returns a World from a text map, with the given cities destroyed.
+func newWorld(t *testing.T, worldDef string, directed bool, destroyed ...string) *world.World {
+	w, err := world.NewFromReader(strings.NewReader(worldDef), directed)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	for _, name := range destroyed {
+		w.DestroyCity(w.Cities[name])
+	}
+
+	return w
+}
+
+func names(cities []*world.City) []string {
+	result := make([]string, len(cities))
+	for i, c := range cities {
+		result[i] = c.Name
+	}
+	return result
+}
+
+func componentNames(components [][]*world.City) [][]string {
+	result := make([][]string, len(components))
+	for i, c := range components {
+		result[i] = names(c)
+	}
+	return result
+}
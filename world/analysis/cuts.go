@@ -0,0 +1,117 @@
+package analysis
+
+import (
+	"sort"
+
+	"github.com/santihernandezc/alien-invasion/world"
+)
+
+// ArticulationPoints returns the cities whose destruction splits the component they're in,
+// taking roads both ways, sorted by name.
+func ArticulationPoints(w *world.World) []*world.City {
+	g := newGraph(w)
+	cuts := g.cuts()
+
+	var points []*world.City
+	for i, city := range g.cities {
+		if cuts.points[i] {
+			points = append(points, city)
+		}
+	}
+
+	return points
+}
+
+// Bridges returns the roads whose loss splits the component they're in, taking roads both ways.
+// Roads of undirected Worlds go from the city that comes first by name, and a road of a directed
+// World isn't a bridge if there's another one between the same cities, in either direction.
+// Roads are sorted by their cities.
+func Bridges(w *world.World) []world.Road {
+	g := newGraph(w)
+	cuts := g.cuts()
+
+	var edges []edge
+	for id, e := range cuts.edges {
+		if cuts.bridges[id] {
+			edges = append(edges, e)
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].from != edges[j].from {
+			return edges[i].from < edges[j].from
+		}
+		return edges[i].to < edges[j].to
+	})
+
+	var bridges []world.Road
+	for _, e := range edges {
+		bridges = append(bridges, world.Road{From: g.cities[e.from], To: g.cities[e.to]})
+	}
+	return bridges
+}
+
+// cuts are the articulation points and bridges of a graph.
+type cuts struct {
+	edges   []edge
+	points  []bool
+	bridges []bool
+}
+
+// cuts finds the articulation points and bridges of the graph with a depth-first search,
+// following the roads both ways. Roads are told apart by their index, so that two roads
+// between the same cities are never bridges.
+func (g *graph) cuts() cuts {
+	c := cuts{edges: g.edges(), points: make([]bool, len(g.cities))}
+	c.bridges = make([]bool, len(c.edges))
+	adjacent := g.undirected(c.edges)
+
+	// order is when each city was first visited, starting at 1, and low the earliest
+	// city reachable from it without going back through the road it was reached from
+	order := make([]int, len(g.cities))
+	low := make([]int, len(g.cities))
+	next := 1
+
+	var visit func(i, parentEdge int)
+	visit = func(i, parentEdge int) {
+		order[i], low[i] = next, next
+		next++
+
+		children := 0
+		for _, e := range adjacent[i] {
+			if e.id == parentEdge {
+				continue
+			}
+			if order[e.to] != 0 {
+				if order[e.to] < low[i] {
+					low[i] = order[e.to]
+				}
+				continue
+			}
+
+			children++
+			visit(e.to, e.id)
+			if low[e.to] < low[i] {
+				low[i] = low[e.to]
+			}
+			if low[e.to] > order[i] {
+				c.bridges[e.id] = true
+			}
+			if parentEdge >= 0 && low[e.to] >= order[i] {
+				c.points[i] = true
+			}
+		}
+
+		// The root of the search splits its component if it has more than one subtree
+		if parentEdge < 0 && children > 1 {
+			c.points[i] = true
+		}
+	}
+
+	for i := range g.cities {
+		if order[i] == 0 {
+			visit(i, -1)
+		}
+	}
+
+	return c
+}
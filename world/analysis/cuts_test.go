@@ -0,0 +1,111 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArticulationPoints(t *testing.T) {
+	tests := []struct {
+		name      string
+		worldDef  string
+		directed  bool
+		destroyed []string
+		expected  []string
+	}{
+		{
+			"undirected world",
+			undirectedDef,
+			false,
+			nil,
+			[]string{"B"},
+		},
+		{
+			"cycle",
+			"A east=B\nB east=C\nC north=A",
+			false,
+			nil,
+			[]string{},
+		},
+		{
+			"after destroying a city of the cycle",
+			undirectedDef,
+			false,
+			[]string{"D"},
+			[]string{"B"},
+		},
+		{
+			"line",
+			"A east=B\nB east=C\nC east=D",
+			false,
+			nil,
+			[]string{"B", "C"},
+		},
+		{
+			"directed world, roads taken both ways",
+			directedDef,
+			true,
+			nil,
+			[]string{"C"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			w := newWorld(tt, test.worldDef, test.directed, test.destroyed...)
+			assert.Equal(tt, test.expected, names(ArticulationPoints(w)))
+		})
+	}
+}
+
+func TestBridges(t *testing.T) {
+	tests := []struct {
+		name      string
+		worldDef  string
+		directed  bool
+		destroyed []string
+		expected  [][2]string
+	}{
+		{
+			"undirected world",
+			undirectedDef,
+			false,
+			nil,
+			[][2]string{{"A", "B"}, {"E", "F"}},
+		},
+		{
+			"after destroying a city of the cycle",
+			undirectedDef,
+			false,
+			[]string{"D"},
+			[][2]string{{"A", "B"}, {"B", "C"}, {"E", "F"}},
+		},
+		{
+			"directed world",
+			directedDef,
+			true,
+			nil,
+			[][2]string{{"C", "D"}},
+		},
+		{
+			"directed world, road back",
+			directedDef + "\nD west=C",
+			true,
+			nil,
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			w := newWorld(tt, test.worldDef, test.directed, test.destroyed...)
+
+			var bridges [][2]string
+			for _, r := range Bridges(w) {
+				bridges = append(bridges, [2]string{r.From.Name, r.To.Name})
+			}
+			assert.Equal(tt, test.expected, bridges)
+		})
+	}
+}
@@ -0,0 +1,116 @@
+// Package analysis computes properties of the graph formed by the cities of a World that
+// weren't destroyed and the roads between them, such as its components, the cities and roads
+// holding it together, shortest paths and how central each city is.
+package analysis
+
+import "github.com/santihernandezc/alien-invasion/world"
+
+// graph is the graph of a World, with cities sorted by name and identified by their index,
+// so every analysis has a reproducible result.
+type graph struct {
+	cities   []*world.City
+	index    map[*world.City]int
+	directed bool
+	// out has the cities each city has roads to, once each and in the order of its neighbors
+	out [][]int
+}
+
+// newGraph returns the graph of a World, along with the given extra roads between its cities,
+// which are taken both ways in undirected Worlds.
+func newGraph(w *world.World, extra ...world.Road) *graph {
+	g := graph{
+		cities:   w.SortedCities(),
+		index:    make(map[*world.City]int, len(w.Cities)),
+		directed: w.Directed(),
+	}
+	for i, city := range g.cities {
+		g.index[city] = i
+	}
+
+	g.out = make([][]int, len(g.cities))
+	for i, city := range g.cities {
+		g.out[i] = make([]int, 0, len(city.Neighbors))
+		for _, n := range city.Neighbors {
+			if j, ok := g.index[n]; ok && !g.has(i, j) {
+				g.out[i] = append(g.out[i], j)
+			}
+		}
+	}
+
+	for _, r := range extra {
+		from, ok := g.index[r.From]
+		if !ok {
+			continue
+		}
+		to, ok := g.index[r.To]
+		if !ok {
+			continue
+		}
+
+		if !g.has(from, to) {
+			g.out[from] = append(g.out[from], to)
+		}
+		if !g.directed && !g.has(to, from) {
+			g.out[to] = append(g.out[to], from)
+		}
+	}
+
+	return &g
+}
+
+// edge is a road between two cities, taken both ways.
+type edge struct {
+	from, to int
+}
+
+// edges returns the roads of the graph, taking every road of a directed World as a different
+// one, and the roads of an undirected World once for both ways. Roads from a city to itself are left out.
+func (g *graph) edges() []edge {
+	var edges []edge
+	for i, out := range g.out {
+		for _, j := range out {
+			if i == j || (!g.directed && j < i && g.has(j, i)) {
+				continue
+			}
+			edges = append(edges, edge{from: i, to: j})
+		}
+	}
+
+	return edges
+}
+
+// has reports whether there's a road from one city to another.
+func (g *graph) has(from, to int) bool {
+	for _, j := range g.out[from] {
+		if j == to {
+			return true
+		}
+	}
+	return false
+}
+
+// halfEdge is one end of an edge, as seen from the other end.
+type halfEdge struct {
+	to, id int
+}
+
+// undirected returns the cities connected to each city by a road in either direction,
+// along with the index of the road in edges.
+func (g *graph) undirected(edges []edge) [][]halfEdge {
+	adjacent := make([][]halfEdge, len(g.cities))
+	for id, e := range edges {
+		adjacent[e.from] = append(adjacent[e.from], halfEdge{to: e.to, id: id})
+		adjacent[e.to] = append(adjacent[e.to], halfEdge{to: e.from, id: id})
+	}
+
+	return adjacent
+}
+
+// citiesAt returns the cities with the given indexes.
+func (g *graph) citiesAt(indexes []int) []*world.City {
+	cities := make([]*world.City, len(indexes))
+	for i, index := range indexes {
+		cities[i] = g.cities[index]
+	}
+	return cities
+}
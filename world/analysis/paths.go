@@ -0,0 +1,111 @@
+package analysis
+
+import (
+	"sort"
+
+	"github.com/santihernandezc/alien-invasion/world"
+)
+
+// Distances returns the amount of roads on the shortest path from a city to every city it
+// can reach, following the direction of the roads. Cities that can't be reached are left out.
+func Distances(w *world.World, from *world.City) map[*world.City]int {
+	g := newGraph(w)
+	start, ok := g.index[from]
+	if !ok {
+		return map[*world.City]int{}
+	}
+
+	dist, _ := g.bfs(start)
+	distances := make(map[*world.City]int, len(g.cities))
+	for i, d := range dist {
+		if d >= 0 {
+			distances[g.cities[i]] = d
+		}
+	}
+
+	return distances
+}
+
+// ShortestPath returns the cities on a path from one city to another with the least roads,
+// including both of them, or nil if there's none. Among paths with as many roads, the one
+// taking the neighbors that come first by name is returned.
+func ShortestPath(w *world.World, from, to *world.City) []*world.City {
+	g := newGraph(w)
+	start, ok := g.index[from]
+	if !ok {
+		return nil
+	}
+	end, ok := g.index[to]
+	if !ok {
+		return nil
+	}
+
+	dist, prev := g.bfs(start)
+	if dist[end] < 0 {
+		return nil
+	}
+	return g.citiesAt(path(prev, end))
+}
+
+// Diameter returns the amount of roads on the longest of the shortest paths between two cities,
+// among the cities that can reach each other, along with that path. Among paths with as many
+// roads, the one starting and then ending in the cities that come first by name is returned.
+func Diameter(w *world.World) (diameter int, longest []*world.City) {
+	g := newGraph(w)
+	for start := range g.cities {
+		dist, prev := g.bfs(start)
+		for end, d := range dist {
+			if d > diameter || (longest == nil && d == 0) {
+				diameter = d
+				longest = g.citiesAt(path(prev, end))
+			}
+		}
+	}
+
+	return diameter, longest
+}
+
+// bfs finds the shortest paths from a city with a breadth-first search, which visits
+// neighbors sorted by name. It returns the amount of roads to each city, or -1 if it's
+// unreachable, and the city before each one on its path.
+func (g *graph) bfs(start int) (dist []int, prev []int) {
+	dist = make([]int, len(g.cities))
+	prev = make([]int, len(g.cities))
+	for i := range dist {
+		dist[i], prev[i] = -1, -1
+	}
+
+	dist[start] = 0
+	for queue := []int{start}; len(queue) > 0; queue = queue[1:] {
+		i := queue[0]
+		for _, j := range g.sortedOut(i) {
+			if dist[j] < 0 {
+				dist[j] = dist[i] + 1
+				prev[j] = i
+				queue = append(queue, j)
+			}
+		}
+	}
+
+	return dist, prev
+}
+
+// sortedOut returns the cities a city has roads to, sorted by name.
+func (g *graph) sortedOut(i int) []int {
+	out := append([]int(nil), g.out[i]...)
+	sort.Ints(out)
+	return out
+}
+
+// path returns the cities on the path ending in a city, following prev back to its start.
+func path(prev []int, end int) []int {
+	var p []int
+	for i := end; i >= 0; i = prev[i] {
+		p = append(p, i)
+	}
+	for i, j := 0, len(p)-1; i < j; i, j = i+1, j-1 {
+		p[i], p[j] = p[j], p[i]
+	}
+
+	return p
+}
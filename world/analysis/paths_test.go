@@ -0,0 +1,166 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistances(t *testing.T) {
+	tests := []struct {
+		name     string
+		worldDef string
+		directed bool
+		from     string
+		expected map[string]int
+	}{
+		{
+			"undirected world",
+			undirectedDef,
+			false,
+			"A",
+			map[string]int{"A": 0, "B": 1, "C": 2, "D": 2},
+		},
+		{
+			"directed world",
+			directedDef,
+			true,
+			"A",
+			map[string]int{"A": 0, "B": 1, "C": 2, "D": 3},
+		},
+		{
+			"directed world, no roads",
+			directedDef,
+			true,
+			"D",
+			map[string]int{"D": 0},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			w := newWorld(tt, test.worldDef, test.directed)
+
+			distances := make(map[string]int)
+			for city, d := range Distances(w, w.Cities[test.from]) {
+				distances[city.Name] = d
+			}
+			assert.Equal(tt, test.expected, distances)
+		})
+	}
+}
+
+func TestShortestPath(t *testing.T) {
+	tests := []struct {
+		name      string
+		worldDef  string
+		directed  bool
+		destroyed []string
+		from, to  string
+		expected  []string
+	}{
+		{
+			"same city",
+			undirectedDef,
+			false,
+			nil,
+			"A", "A",
+			[]string{"A"},
+		},
+		{
+			"undirected world",
+			undirectedDef,
+			false,
+			nil,
+			"A", "D",
+			[]string{"A", "B", "D"},
+		},
+		{
+			"around a destroyed city",
+			"A east=B\nB east=C\nA south=D\nD east=E\nE north=C",
+			false,
+			[]string{"B"},
+			"A", "C",
+			[]string{"A", "D", "E", "C"},
+		},
+		{
+			"another component",
+			undirectedDef,
+			false,
+			nil,
+			"A", "E",
+			nil,
+		},
+		{
+			"directed world, following the roads",
+			directedDef,
+			true,
+			nil,
+			"B", "A",
+			[]string{"B", "C", "A"},
+		},
+		{
+			"directed world, no way back",
+			directedDef,
+			true,
+			nil,
+			"D", "A",
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			w := newWorld(tt, test.worldDef, test.directed, test.destroyed...)
+
+			path := ShortestPath(w, w.Cities[test.from], w.Cities[test.to])
+			if test.expected == nil {
+				assert.Nil(tt, path)
+				return
+			}
+			assert.Equal(tt, test.expected, names(path))
+		})
+	}
+}
+
+func TestDiameter(t *testing.T) {
+	tests := []struct {
+		name     string
+		worldDef string
+		directed bool
+		expected int
+		path     []string
+	}{
+		{
+			"undirected world",
+			undirectedDef,
+			false,
+			2,
+			[]string{"A", "B", "C"},
+		},
+		{
+			"directed world",
+			directedDef,
+			true,
+			3,
+			[]string{"A", "B", "C", "D"},
+		},
+		{
+			"single city",
+			"A",
+			false,
+			0,
+			[]string{"A"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			w := newWorld(tt, test.worldDef, test.directed)
+
+			diameter, path := Diameter(w)
+			assert.Equal(tt, test.expected, diameter)
+			assert.Equal(tt, test.path, names(path))
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package world
+
+import "fmt"
+
+// Attributes are the optional properties of a city, which decide how it
+// withstands fights. Every attribute is 0 if the map doesn't set it.
+type Attributes struct {
+	// Population is the amount of people living in the city
+	Population int `json:"population,omitempty"`
+	// Defense is the probability of the city repelling a fight, between 0 and 1
+	Defense float64 `json:"defense,omitempty"`
+	// HitPoints is the amount of fights it takes to destroy the city, a single one if it's 0
+	HitPoints int `json:"hit_points,omitempty"`
+	// Value is the strategic value of the city
+	Value float64 `json:"value,omitempty"`
+}
+
+// MaxHitPoints returns the amount of fights it takes to destroy the city, which is at least one.
+func (a Attributes) MaxHitPoints() int {
+	if a.HitPoints < 1 {
+		return 1
+	}
+	return a.HitPoints
+}
+
+// validate checks that the attributes of a city are in range.
+func (a Attributes) validate(city string) error {
+	switch {
+	case a.Population < 0:
+		return fmt.Errorf("invalid population for %s: %d", city, a.Population)
+	case a.Defense < 0 || a.Defense > 1:
+		return fmt.Errorf("invalid defense for %s: must be between 0 and 1, got %g", city, a.Defense)
+	case a.HitPoints < 0:
+		return fmt.Errorf("invalid hit points for %s: %d", city, a.HitPoints)
+	case a.Value < 0:
+		return fmt.Errorf("invalid value for %s: %g", city, a.Value)
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package world
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// WriteJSON writes the World as a JSON map, which can be read with NewFromBytes.
+// Directions and lengths of roads, positions of pinned cities and attributes are included.
+func (w *World) WriteJSON(out io.Writer) error {
+	defs := make([]jsonCityDefinition, 0, len(w.Cities))
+	for _, city := range w.SortedCities() {
+		def := jsonCityDefinition{
+			Name:       city.Name,
+			Neighbors:  make([]jsonRoad, 0, len(city.neighborMap)),
+			Attributes: city.Attributes,
+		}
+		if city.pinned {
+			x, y := city.Position.X, city.Position.Y
+			def.X, def.Y = &x, &y
+		}
+
+		for _, n := range sortedNeighbors(city) {
+			def.Neighbors = append(def.Neighbors, jsonRoad{Name: n.Name, Direction: string(city.neighborMap[n]), Length: city.lengths[n]})
+		}
+		defs = append(defs, def)
+	}
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(defs)
+}
+
+// WriteText writes the World in the text map format, which can be read with NewFromReader.
+// The text format needs every road to have a direction.
+func (w *World) WriteText(out io.Writer) error {
+	for _, city := range w.SortedCities() {
+		for _, n := range sortedNeighbors(city) {
+			if city.neighborMap[n] == "" {
+				return fmt.Errorf("road from %s to %s has no direction, which the text format requires", city.Name, n.Name)
+			}
+		}
+	}
+
+	_, err := io.WriteString(out, w.String())
+	return err
+}
+
+// sortedNeighbors returns the cities connected to a city, sorted by name.
+func sortedNeighbors(city *City) []*City {
+	neighbors := make([]*City, 0, len(city.neighborMap))
+	for n := range city.neighborMap {
+		neighbors = append(neighbors, n)
+	}
+	sort.Slice(neighbors, func(i, j int) bool { return neighbors[i].Name < neighbors[j].Name })
+
+	return neighbors
+}
+
+// DOTOptions add information about a simulation to the DOT representation of a World.
+type DOTOptions struct {
+	// Aliens maps city names with the IDs of the aliens in them, shown in their labels.
+	Aliens map[string][]int
+	// Positions pins the cities to their positions, for layout engines supporting it like neato.
+	Positions bool
+}
+
+// compassPorts maps directions with the Graphviz compass point of the side of a city they leave from.
+var compassPorts = map[direction]string{
+	north: "n",
+	south: "s",
+	east:  "e",
+	west:  "w",
+}
+
+// WriteDOT writes the World in the Graphviz DOT format. Destroyed cities are drawn dashed,
+// along with the roads they had, and the directions of roads are used as port hints so that
+// e.g. a road going north leaves from the top of the city.
+func (w *World) WriteDOT(out io.Writer, opts DOTOptions) error {
+	graphType, edgeOp := "graph", "--"
+	if w.directed {
+		graphType, edgeOp = "digraph", "->"
+	}
+
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "%s world {\n", graphType)
+	fmt.Fprintln(b, "  node [shape=circle];")
+
+	destroyed := make([]*City, len(w.DestroyedCities))
+	copy(destroyed, w.DestroyedCities)
+	sort.Slice(destroyed, func(i, j int) bool { return destroyed[i].Name < destroyed[j].Name })
+
+	writeNode := func(city *City, attrs ...string) {
+		label := city.Name
+		if aliens := opts.Aliens[city.Name]; len(aliens) > 0 {
+			names := make([]string, len(aliens))
+			for i, id := range aliens {
+				names[i] = fmt.Sprintf("Alien %d", id)
+			}
+			label += "\n" + strings.Join(names, ", ")
+			attrs = append(attrs, "style=filled", "fillcolor=lightcoral")
+		}
+		attrs = append([]string{"label=" + strconv.Quote(label)}, attrs...)
+		if opts.Positions {
+			// Graphviz's y axis points up
+			attrs = append(attrs, fmt.Sprintf("pos=\"%g,%g!\"", city.Position.X, 0-city.Position.Y))
+		}
+
+		fmt.Fprintf(b, "  %s [%s];\n", strconv.Quote(city.Name), strings.Join(attrs, ", "))
+	}
+
+	for _, city := range w.SortedCities() {
+		writeNode(city)
+	}
+	for _, city := range destroyed {
+		writeNode(city, "style=dashed", "color=gray", "fontcolor=gray")
+	}
+
+	// Undirected roads are written once, from the city that comes first
+	written := make(map[[2]*City]bool)
+	writeEdge := func(from, to *City, attrs ...string) {
+		if written[[2]*City{from, to}] || (!w.directed && written[[2]*City{to, from}]) {
+			return
+		}
+		written[[2]*City{from, to}] = true
+
+		fromPort, toPort := "", ""
+		if dir, ok := from.neighborMap[to]; ok && dir != "" {
+			fromPort = ":" + compassPorts[dir]
+			toPort = ":" + compassPorts[oppositeDirectionMap[dir]]
+		}
+		fmt.Fprintf(b, "  %s%s %s %s%s", strconv.Quote(from.Name), fromPort, edgeOp, strconv.Quote(to.Name), toPort)
+		if len(attrs) > 0 {
+			fmt.Fprintf(b, " [%s]", strings.Join(attrs, ", "))
+		}
+		fmt.Fprintln(b, ";")
+	}
+
+	for _, city := range w.SortedCities() {
+		for _, n := range sortedNeighbors(city) {
+			writeEdge(city, n)
+		}
+	}
+	for _, city := range destroyed {
+		for _, n := range sortedNeighbors(city) {
+			writeEdge(city, n, "style=dashed", "color=gray")
+		}
+	}
+	fmt.Fprintln(b, "}")
+
+	_, err := io.WriteString(out, b.String())
+	return err
+}
@@ -0,0 +1,139 @@
+package world
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteJSON(t *testing.T) {
+	w, err := NewFromBytes([]byte(`[{"name": "Gerli", "x": 100, "y": 200, "population": 1000, "hit_points": 2, "neighbors": [{"name": "Lanús", "direction": "east"}, "Avellaneda"]}]`), false)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var buf bytes.Buffer
+	if !assert.NoError(t, w.WriteJSON(&buf)) {
+		return
+	}
+
+	expected := `[
+  {
+    "name": "Avellaneda",
+    "neighbors": [
+      "Gerli"
+    ]
+  },
+  {
+    "name": "Gerli",
+    "neighbors": [
+      "Avellaneda",
+      {
+        "name": "Lanús",
+        "direction": "east"
+      }
+    ],
+    "x": 100,
+    "y": 200,
+    "population": 1000,
+    "hit_points": 2
+  },
+  {
+    "name": "Lanús",
+    "neighbors": [
+      {
+        "name": "Gerli",
+        "direction": "west"
+      }
+    ]
+  }
+]
+`
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestWriteText(t *testing.T) {
+	t.Run("roads with directions", func(tt *testing.T) {
+		worldDef := "A north=B east=C\nB east=D\nC north=D\n"
+		w, err := NewFromReader(strings.NewReader(worldDef), false)
+		if !assert.NoError(tt, err) {
+			return
+		}
+
+		var buf bytes.Buffer
+		if !assert.NoError(tt, w.WriteText(&buf)) {
+			return
+		}
+		loaded, err := NewFromReader(&buf, false)
+		if assert.NoError(tt, err) {
+			assert.Equal(tt, w.String(), loaded.String())
+		}
+	})
+
+	t.Run("roads without directions", func(tt *testing.T) {
+		w, err := NewFromBytes([]byte(`[{"name": "Gerli", "neighbors": ["Lanús"]}]`), false)
+		if !assert.NoError(tt, err) {
+			return
+		}
+
+		var buf bytes.Buffer
+		err = w.WriteText(&buf)
+		assert.EqualError(tt, err, "road from Gerli to Lanús has no direction, which the text format requires")
+	})
+}
+
+func TestWriteDOT(t *testing.T) {
+	t.Run("non-directed", func(tt *testing.T) {
+		w, err := NewFromReader(strings.NewReader("A north=B east=C\nB east=D\nC north=D"), false)
+		if !assert.NoError(tt, err) {
+			return
+		}
+		w.DeleteCityAndRoads(w.Cities["D"])
+
+		var buf bytes.Buffer
+		if !assert.NoError(tt, w.WriteDOT(&buf, DOTOptions{Aliens: map[string][]int{"A": {1, 2}}})) {
+			return
+		}
+
+		expected := `graph world {
+  node [shape=circle];
+  "A" [label="A\nAlien 1, Alien 2", style=filled, fillcolor=lightcoral];
+  "B" [label="B"];
+  "C" [label="C"];
+  "D" [label="D", style=dashed, color=gray, fontcolor=gray];
+  "A":n -- "B":s;
+  "A":e -- "C":w;
+  "D":w -- "B":e [style=dashed, color=gray];
+  "D":s -- "C":n [style=dashed, color=gray];
+}
+`
+		assert.Equal(tt, expected, buf.String())
+	})
+
+	t.Run("directed", func(tt *testing.T) {
+		w, err := NewFromReader(strings.NewReader("A north=B\nB south=A east=C"), true)
+		if !assert.NoError(tt, err) {
+			return
+		}
+		w.Cities["A"].Position = Position{X: 10, Y: 20}
+
+		var buf bytes.Buffer
+		if !assert.NoError(tt, w.WriteDOT(&buf, DOTOptions{Positions: true})) {
+			return
+		}
+
+		expected := `digraph world {
+  node [shape=circle];
+  "A" [label="A", pos="10,-20!"];
+  "B" [label="B", pos="0,0!"];
+  "C" [label="C", pos="0,0!"];
+  "A":n -> "B":s;
+  "B":s -> "A":n;
+  "B":e -> "C":w;
+}
+`
+		assert.Equal(tt, expected, buf.String())
+	})
+}
@@ -0,0 +1,371 @@
+package world
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Topologies of the generated maps.
+const (
+	TopologyGrid      = "grid"
+	TopologyRing      = "ring"
+	TopologyTree      = "tree"
+	TopologyRandom    = "random"
+	TopologyGeometric = "geometric"
+	TopologyScaleFree = "scale-free"
+	TopologyClusters  = "clusters"
+)
+
+// Defaults for the generation options.
+const (
+	defaultDegree   = 3
+	defaultClusters = 3
+)
+
+// NameSource returns the name of the i-th generated city, starting at 0.
+// Names must be unique and can't contain spaces.
+type NameSource func(i int) string
+
+// GenerateOptions define the map built by Generate.
+type GenerateOptions struct {
+	// Topology is the shape of the road network, one of the Topology constants.
+	Topology string
+	// Cities is the amount of cities in the map.
+	Cities int
+	// Seed is used for every random decision, so the same options always generate the same map.
+	Seed int64
+	// Names names the cities, defaults to NumberedNames("City").
+	Names NameSource
+	// Degree is the average amount of roads per city in the random, geometric, scale-free
+	// and clusters topologies, defaults to 3. It can't be more than 4, the most roads a city can
+	// have, and the average might be lower since roads that would exceed them are left out.
+	Degree float64
+	// Clusters is the amount of disconnected clusters in the clusters topology, defaults to 3.
+	Clusters int
+}
+
+// Generate returns a new non-directed World built from the given options.
+// Only the grid, ring and tree topologies give a direction to every road, which the text format needs.
+// Cities in the geometric topology are pinned to the positions used to connect them,
+// inside an area of 800x450.
+func Generate(opts GenerateOptions) (*World, error) {
+	if opts.Cities < 1 {
+		return nil, fmt.Errorf("invalid amount of cities: %d", opts.Cities)
+	}
+	if opts.Names == nil {
+		opts.Names = NumberedNames("City")
+	}
+	if opts.Degree <= 0 {
+		opts.Degree = defaultDegree
+	}
+	if opts.Degree > float64(maxRoads) {
+		return nil, fmt.Errorf("invalid degree: %g, cities can't have more than %d roads", opts.Degree, maxRoads)
+	}
+	if opts.Clusters < 1 {
+		opts.Clusters = defaultClusters
+	}
+
+	g := generator{
+		world: &World{Cities: make(map[string]*City, opts.Cities)},
+		rng:   rand.New(rand.NewSource(opts.Seed)),
+		names: make([]string, opts.Cities),
+	}
+
+	// Create every city up front, so the ones without roads are part of the World too
+	for i := range g.names {
+		name := opts.Names(i)
+		if name == "" || strings.IndexFunc(name, unicode.IsSpace) >= 0 || strings.Contains(name, "=") {
+			return nil, fmt.Errorf("invalid name for city %d: %q", i, name)
+		}
+		if _, ok := g.world.Cities[name]; ok {
+			return nil, fmt.Errorf("duplicated name for city %d: %q", i, name)
+		}
+
+		g.names[i] = name
+		if err := g.world.addCityAndRoads(&cityDefinition{name: name}); err != nil {
+			return nil, err
+		}
+	}
+
+	switch opts.Topology {
+	case TopologyGrid:
+		g.grid()
+	case TopologyRing:
+		g.ring()
+	case TopologyTree:
+		g.tree()
+	case TopologyRandom:
+		g.random(0, opts.Cities, opts.Degree)
+	case TopologyGeometric:
+		g.geometric(opts.Degree)
+	case TopologyScaleFree:
+		g.scaleFree(opts.Degree)
+	case TopologyClusters:
+		g.clusters(opts.Clusters, opts.Degree)
+	default:
+		return nil, fmt.Errorf("invalid topology: %q", opts.Topology)
+	}
+
+	return g.world, nil
+}
+
+// NumberedNames names cities with a prefix followed by their number, starting at 1.
+func NumberedNames(prefix string) NameSource {
+	return func(i int) string {
+		return prefix + strconv.Itoa(i+1)
+	}
+}
+
+// syllables used by SyllableNames.
+var syllables = []string{
+	"ba", "be", "bo", "ca", "ce", "da", "do", "fa",
+	"ga", "go", "la", "le", "li", "lo", "ma", "me",
+	"mi", "na", "ne", "no", "pa", "pe", "ra", "re",
+	"ri", "ro", "sa", "se", "ta", "te", "to", "va",
+}
+
+// SyllableNames names cities with made-up words built from the city number,
+// e.g. "Baba", "Babe" and "Babo", which are unique and have at least two syllables.
+func SyllableNames() NameSource {
+	return func(i int) string {
+		// Write the number in base len(syllables), without zero so every name is different,
+		// skipping the numbers with a single digit
+		var parts []string
+		for n := i + len(syllables); ; n = n/len(syllables) - 1 {
+			parts = append([]string{syllables[n%len(syllables)]}, parts...)
+			if n < len(syllables) {
+				break
+			}
+		}
+
+		name := strings.Join(parts, "")
+		return strings.ToUpper(name[:1]) + name[1:]
+	}
+}
+
+// ListNames names cities from a list, in order. Names are reused with a number
+// appended when the list is exhausted, e.g. "Gerli", "Lanús", "Gerli2", "Lanús2".
+func ListNames(names []string) NameSource {
+	return func(i int) string {
+		name := names[i%len(names)]
+		if round := i / len(names); round > 0 {
+			name += strconv.Itoa(round + 1)
+		}
+		return name
+	}
+}
+
+// generator builds the roads of a generated World.
+type generator struct {
+	world *World
+	rng   *rand.Rand
+	// names are the names of the cities, by number
+	names []string
+}
+
+// connect adds a road between the i-th and j-th cities. dir is the direction of j from i,
+// and it can be empty.
+func (g *generator) connect(i, j int, dir direction) {
+	_ = g.world.addCityAndRoads(&cityDefinition{
+		name:        g.names[i],
+		neighbors:   []string{g.names[j]},
+		neighborMap: map[string]direction{g.names[j]: dir},
+	})
+}
+
+// link adds a road without a direction between the i-th and j-th cities, unless one of them
+// has as many roads as a city can have already, and reports whether it was added.
+func (g *generator) link(i, j int) bool {
+	if g.full(i) || g.full(j) {
+		return false
+	}
+	g.connect(i, j, "")
+	return true
+}
+
+// full reports whether the i-th city has as many roads as a city can have.
+func (g *generator) full(i int) bool {
+	return len(g.world.Cities[g.names[i]].neighborMap) >= maxRoads
+}
+
+// grid places the cities in rows, connecting each one with the cities around it.
+func (g *generator) grid() {
+	n := len(g.names)
+	columns := int(math.Ceil(math.Sqrt(float64(n))))
+	for i := 0; i < n; i++ {
+		if (i+1)%columns != 0 && i+1 < n {
+			g.connect(i, i+1, east)
+		}
+		if i+columns < n {
+			g.connect(i, i+columns, south)
+		}
+	}
+}
+
+// ring connects each city with the next one, and the last one with the first one.
+func (g *generator) ring() {
+	n := len(g.names)
+	if n < 2 {
+		return
+	}
+	for i := 0; i < n-1; i++ {
+		g.connect(i, i+1, east)
+	}
+	if n > 2 {
+		g.connect(n-1, 0, east)
+	}
+}
+
+// tree connects each city with a random city before it, using one of its free directions.
+func (g *generator) tree() {
+	// free are the cities before the current one with at least one direction left
+	free := []int{0}
+	for i := 1; i < len(g.names); i++ {
+		k := g.rng.Intn(len(free))
+		parent := free[k]
+
+		// Take a random direction among the free ones
+		var dirs []direction
+		for _, dir := range []direction{north, south, east, west} {
+			if !g.uses(parent, dir) {
+				dirs = append(dirs, dir)
+			}
+		}
+		g.connect(parent, i, dirs[g.rng.Intn(len(dirs))])
+
+		if len(dirs) == 1 {
+			free = append(free[:k], free[k+1:]...)
+		}
+		free = append(free, i)
+	}
+}
+
+func (g *generator) uses(i int, dir direction) bool {
+	for _, d := range g.world.Cities[g.names[i]].neighborMap {
+		if d == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// random connects each pair of cities between from and to with the same probability,
+// so that cities have the given amount of roads on average (Erdős–Rényi).
+func (g *generator) random(from, to int, degree float64) {
+	if to-from < 2 {
+		return
+	}
+
+	p := math.Min(degree/float64(to-from-1), 1)
+	for i := from; i < to; i++ {
+		for j := i + 1; j < to; j++ {
+			if g.rng.Float64() < p {
+				g.link(i, j)
+			}
+		}
+	}
+}
+
+// geometric places the cities in random positions and connects the ones closer than a radius,
+// chosen so that cities have the given amount of roads on average.
+func (g *generator) geometric(degree float64) {
+	const width, height = 800, 450
+
+	n := len(g.names)
+	points := make([]Position, n)
+	for i := range points {
+		points[i] = Position{X: g.rng.Float32(), Y: g.rng.Float32()}
+
+		city := g.world.Cities[g.names[i]]
+		city.Position = Position{
+			X: points[i].X*(width-2*layoutMargin) + layoutMargin,
+			Y: points[i].Y*(height-2*layoutMargin) + layoutMargin,
+		}
+		city.pinned = true
+	}
+
+	// Connect the closest cities first, so the ones left out for having too many roads are far away
+	type pair struct {
+		i, j     int
+		distance float64
+	}
+	var pairs []pair
+	radius := math.Sqrt(degree / (math.Pi * float64(n)))
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			dx, dy := float64(points[i].X-points[j].X), float64(points[i].Y-points[j].Y)
+			if d := math.Hypot(dx, dy); d <= radius {
+				pairs = append(pairs, pair{i, j, d})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(a, b int) bool { return pairs[a].distance < pairs[b].distance })
+
+	for _, p := range pairs {
+		g.link(p.i, p.j)
+	}
+}
+
+// scaleFree adds the cities one by one, connecting each one with cities chosen with a
+// probability proportional to their amount of roads (Barabási–Albert).
+func (g *generator) scaleFree(degree float64) {
+	n := len(g.names)
+	m := int(math.Max(1, math.Round(degree/2)))
+
+	// ends has each city once per road, to choose them proportionally to their roads,
+	// and open has the cities added so far that can take more roads
+	var ends, open []int
+	for i := 1; i < n; i++ {
+		open = append(open, i-1)
+		targets := make(map[int]bool, m)
+		for len(targets) < m && len(targets) < len(open) {
+			var t int
+			if len(ends) == 0 {
+				t = open[g.rng.Intn(len(open))]
+			} else {
+				t = ends[g.rng.Intn(len(ends))]
+			}
+
+			// Cities with few roads might never be chosen and full ones can't be, pick any city
+			// that can take more roads from time to time
+			if targets[t] || g.full(t) {
+				t = open[g.rng.Intn(len(open))]
+			}
+			targets[t] = true
+		}
+
+		for t := 0; t < i; t++ {
+			if targets[t] && g.link(i, t) {
+				ends = append(ends, i, t)
+			}
+		}
+
+		// Forget the cities that can't take more roads
+		remaining := open[:0]
+		for _, t := range open {
+			if !g.full(t) {
+				remaining = append(remaining, t)
+			}
+		}
+		open = remaining
+	}
+}
+
+// clusters splits the cities in disconnected groups, each of them randomly connected inside.
+func (g *generator) clusters(clusters int, degree float64) {
+	n := len(g.names)
+	for c := 0; c < clusters; c++ {
+		from, to := c*n/clusters, (c+1)*n/clusters
+
+		// Chain the cities so each cluster is connected, then add random roads
+		for i := from; i+1 < to; i++ {
+			g.link(i, i+1)
+		}
+		g.random(from, to, math.Max(degree-2, 0))
+	}
+}
@@ -0,0 +1,225 @@
+package world
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  GenerateOptions
+		check func(tt *testing.T, w *World)
+		err   string
+	}{
+		{
+			"invalid amount of cities",
+			GenerateOptions{Topology: TopologyGrid},
+			nil,
+			"invalid amount of cities: 0",
+		},
+		{
+			"invalid topology",
+			GenerateOptions{Topology: "cube", Cities: 10},
+			nil,
+			`invalid topology: "cube"`,
+		},
+		{
+			"duplicated names",
+			GenerateOptions{Topology: TopologyRing, Cities: 3, Names: func(i int) string { return "Gerli" }},
+			nil,
+			`duplicated name for city 1: "Gerli"`,
+		},
+		{
+			"invalid names",
+			GenerateOptions{Topology: TopologyRing, Cities: 3, Names: NumberedNames("San ")},
+			nil,
+			`invalid name for city 0: "San 1"`,
+		},
+		{
+			"invalid degree",
+			GenerateOptions{Topology: TopologyRandom, Cities: 10, Degree: 5},
+			nil,
+			"invalid degree: 5, cities can't have more than 4 roads",
+		},
+		{
+			"grid",
+			GenerateOptions{Topology: TopologyGrid, Cities: 9},
+			func(tt *testing.T, w *World) {
+				// The center of a 3x3 grid has four roads, the corners two
+				assert.Equal(tt, 4, len(w.Cities["City5"].Neighbors))
+				assert.Equal(tt, 2, len(w.Cities["City1"].Neighbors))
+				assert.Equal(tt, east, w.Cities["City1"].neighborMap[w.Cities["City2"]])
+				assert.Equal(tt, south, w.Cities["City1"].neighborMap[w.Cities["City4"]])
+			},
+			"",
+		},
+		{
+			"ring",
+			GenerateOptions{Topology: TopologyRing, Cities: 5},
+			func(tt *testing.T, w *World) {
+				for _, city := range w.Cities {
+					assert.Equal(tt, 2, len(city.Neighbors))
+				}
+				assert.Equal(tt, west, w.Cities["City1"].neighborMap[w.Cities["City5"]])
+			},
+			"",
+		},
+		{
+			"tree",
+			GenerateOptions{Topology: TopologyTree, Cities: 50, Seed: 3},
+			func(tt *testing.T, w *World) {
+				var roads int
+				for _, city := range w.Cities {
+					roads += len(city.Neighbors)
+					assert.LessOrEqual(tt, len(city.Neighbors), maxRoads)
+				}
+				assert.Equal(tt, 2*49, roads)
+				assert.Equal(tt, 50, reachable(w.Cities["City1"]))
+			},
+			"",
+		},
+		{
+			"random",
+			GenerateOptions{Topology: TopologyRandom, Cities: 200, Degree: 3},
+			func(tt *testing.T, w *World) {
+				assert.InDelta(tt, 3, averageDegree(w), 1)
+				assertMaxRoads(tt, w)
+			},
+			"",
+		},
+		{
+			"geometric",
+			GenerateOptions{Topology: TopologyGeometric, Cities: 200, Degree: 3},
+			func(tt *testing.T, w *World) {
+				assert.InDelta(tt, 3, averageDegree(w), 1)
+				assertMaxRoads(tt, w)
+				for _, city := range w.Cities {
+					assert.True(tt, city.pinned)
+				}
+			},
+			"",
+		},
+		{
+			"scale-free",
+			GenerateOptions{Topology: TopologyScaleFree, Cities: 200, Degree: 3},
+			func(tt *testing.T, w *World) {
+				assert.InDelta(tt, 3, averageDegree(w), 1)
+				assertMaxRoads(tt, w)
+				assert.Equal(tt, 200, reachable(w.Cities["City1"]))
+			},
+			"",
+		},
+		{
+			"clusters",
+			GenerateOptions{Topology: TopologyClusters, Cities: 30, Clusters: 3},
+			func(tt *testing.T, w *World) {
+				assert.Equal(tt, 10, reachable(w.Cities["City1"]))
+				assert.Equal(tt, 10, reachable(w.Cities["City30"]))
+				assertMaxRoads(tt, w)
+			},
+			"",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			w, err := Generate(test.opts)
+			if test.err != "" {
+				assert.EqualError(tt, err, test.err)
+				return
+			}
+
+			if !assert.NoError(tt, err) {
+				return
+			}
+			assert.Equal(tt, test.opts.Cities, len(w.Cities))
+			test.check(tt, w)
+		})
+	}
+
+	t.Run("same options generate the same map", func(tt *testing.T) {
+		opts := GenerateOptions{Topology: TopologyScaleFree, Cities: 100, Seed: 42}
+		a, err := Generate(opts)
+		assert.NoError(tt, err)
+		b, err := Generate(opts)
+		assert.NoError(tt, err)
+
+		assert.Equal(tt, jsonMap(tt, a), jsonMap(tt, b))
+	})
+}
+
+func TestNameSources(t *testing.T) {
+	assert.Equal(t, "City1", NumberedNames("City")(0))
+	assert.Equal(t, "Gerli2", ListNames([]string{"Gerli", "Lanús"})(2))
+
+	names := SyllableNames()
+	assert.Equal(t, "Baba", names(0))
+	assert.Equal(t, "Babe", names(1))
+
+	seen := make(map[string]bool)
+	for i := 0; i < 10000; i++ {
+		name := names(i)
+		if seen[name] {
+			t.Fatalf("name %q is repeated", name)
+		}
+		seen[name] = true
+	}
+}
+
+func TestGeneratedRoundTrip(t *testing.T) {
+	for _, topology := range []string{TopologyGrid, TopologyRing, TopologyTree, TopologyScaleFree} {
+		t.Run(topology, func(tt *testing.T) {
+			w, err := Generate(GenerateOptions{Topology: topology, Cities: 30, Seed: 1})
+			if !assert.NoError(tt, err) {
+				return
+			}
+
+			loaded, err := NewFromBytes([]byte(jsonMap(tt, w)), false)
+			if assert.NoError(tt, err) {
+				assert.Equal(tt, jsonMap(tt, w), jsonMap(tt, loaded))
+			}
+		})
+	}
+}
+
+// jsonMap returns a World as a JSON map, which unlike its string has the roads without a direction.
+func jsonMap(t *testing.T, w *World) string {
+	var buf bytes.Buffer
+	assert.NoError(t, w.WriteJSON(&buf))
+	return buf.String()
+}
+
+// assertMaxRoads checks that no city has more roads than a city can have.
+func assertMaxRoads(t *testing.T, w *World) {
+	for name, city := range w.Cities {
+		assert.LessOrEqual(t, len(city.Neighbors), maxRoads, name)
+	}
+}
+
+func averageDegree(w *World) float64 {
+	var roads int
+	for _, city := range w.Cities {
+		roads += len(city.Neighbors)
+	}
+	return float64(roads) / float64(len(w.Cities))
+}
+
+// reachable returns the amount of cities that can be reached from a city, including itself.
+func reachable(from *City) int {
+	visited := map[*City]bool{from: true}
+	queue := []*City{from}
+	for len(queue) > 0 {
+		city := queue[0]
+		queue = queue[1:]
+		for _, n := range city.Neighbors {
+			if !visited[n] {
+				visited[n] = true
+				queue = append(queue, n)
+			}
+		}
+	}
+	return len(visited)
+}
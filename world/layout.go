@@ -0,0 +1,342 @@
+package world
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Names of the built-in layouts.
+const (
+	LayoutRandom = "random"
+	LayoutForce  = "force"
+	LayoutGrid   = "grid"
+)
+
+// layoutMargin is the distance kept between the cities and the borders of the area.
+const layoutMargin = 50
+
+// defaultForceIterations is the amount of iterations of a ForceDirected layout if none is set.
+const defaultForceIterations = 300
+
+// Layout places the cities of a World inside an area of the given dimensions.
+// Cities pinned by the map keep their position.
+type Layout interface {
+	Place(w *World, width int32, height int32)
+}
+
+// NewLayout returns the built-in layout with the given name.
+func NewLayout(name string) (Layout, error) {
+	switch name {
+	case LayoutRandom:
+		return RandomLayout{}, nil
+	case LayoutForce:
+		return ForceDirected{}, nil
+	case LayoutGrid:
+		return Grid{}, nil
+	}
+
+	return nil, fmt.Errorf("invalid layout: %q", name)
+}
+
+// RandomLayout places every city in a random position.
+type RandomLayout struct{}
+
+// Place scatters the cities.
+func (RandomLayout) Place(w *World, width int32, height int32) {
+	w.Scatter(width, height)
+}
+
+// ForceDirected places the cities simulating that roads pull the cities they connect
+// together while every city pushes the rest away, so that connected cities end up close
+// and roads rarely cross each other.
+type ForceDirected struct {
+	// Iterations of the simulation, defaults to 300 if it's 0.
+	Iterations int
+}
+
+// Place runs the simulation starting from random positions.
+func (f ForceDirected) Place(w *World, width int32, height int32) {
+	iterations := f.Iterations
+	if iterations < 1 {
+		iterations = defaultForceIterations
+	}
+
+	cities := w.SortedCities()
+	if len(cities) == 0 {
+		return
+	}
+	w.Scatter(width, height)
+
+	minX, minY := float64(layoutMargin), float64(layoutMargin)
+	maxX, maxY := float64(width-layoutMargin), float64(height-layoutMargin)
+
+	// k is the ideal distance between two cities
+	k := math.Sqrt((maxX - minX) * (maxY - minY) / float64(len(cities)))
+	edges := roads(cities)
+
+	type vector struct{ x, y float64 }
+	displacements := make(map[*City]vector, len(cities))
+
+	temperature := (maxX - minX) / 10
+	cooling := temperature / float64(iterations)
+	for i := 0; i < iterations; i++ {
+		for _, c := range cities {
+			displacements[c] = vector{}
+		}
+
+		// Every pair of cities repel each other
+		for i, c := range cities {
+			for _, o := range cities[i+1:] {
+				dx, dy, d := distance(c, o)
+				force := k * k / d
+				displacements[c] = vector{displacements[c].x + dx/d*force, displacements[c].y + dy/d*force}
+				displacements[o] = vector{displacements[o].x - dx/d*force, displacements[o].y - dy/d*force}
+			}
+		}
+
+		// Roads attract the cities they connect
+		for _, e := range edges {
+			dx, dy, d := distance(e[0], e[1])
+			force := d * d / k
+			displacements[e[0]] = vector{displacements[e[0]].x - dx/d*force, displacements[e[0]].y - dy/d*force}
+			displacements[e[1]] = vector{displacements[e[1]].x + dx/d*force, displacements[e[1]].y + dy/d*force}
+		}
+
+		// Move the cities, never farther than the temperature allows
+		for _, c := range cities {
+			if c.pinned {
+				continue
+			}
+
+			disp := displacements[c]
+			length := math.Hypot(disp.x, disp.y)
+			if length == 0 {
+				continue
+			}
+			step := math.Min(length, temperature)
+
+			c.Position.X = float32(clamp(float64(c.Position.X)+disp.x/length*step, minX, maxX))
+			c.Position.Y = float32(clamp(float64(c.Position.Y)+disp.y/length*step, minY, maxY))
+		}
+
+		temperature -= cooling
+	}
+}
+
+// Grid places the cities in the cells of a grid, following the directions of the roads
+// when they're declared: a city north of another one is placed in the cell above it.
+// Roads without a direction place the neighbor in the closest free cell.
+type Grid struct{}
+
+// cell is a position in a Grid.
+type cell struct{ x, y int }
+
+// Place walks the roads from each city to assign cells, then fits the grid in the area.
+// Disconnected parts of the World are placed side by side.
+func (Grid) Place(w *World, width int32, height int32) {
+	cities := w.SortedCities()
+	if len(cities) == 0 {
+		return
+	}
+
+	adjacent := directedRoads(cities)
+	cells := make(map[*City]cell, len(cities))
+
+	// offset is the first column free for the next disconnected part
+	var offset int
+	for _, start := range cities {
+		if _, ok := cells[start]; ok {
+			continue
+		}
+
+		// Place the part of the World connected to start, walking its roads
+		part := []*City{start}
+		taken := map[cell]bool{{}: true}
+		cells[start] = cell{}
+		for i := 0; i < len(part); i++ {
+			city := part[i]
+			for _, r := range adjacent[city] {
+				if _, ok := cells[r.to]; ok {
+					continue
+				}
+
+				target := cells[city]
+				switch r.dir {
+				case north:
+					target.y--
+				case south:
+					target.y++
+				case east:
+					target.x++
+				case west:
+					target.x--
+				default:
+					target.x++
+				}
+
+				cells[r.to] = freeCell(target, taken)
+				taken[cells[r.to]] = true
+				part = append(part, r.to)
+			}
+		}
+
+		// Move the part to the right of the previous ones
+		minX, maxX := math.MaxInt32, math.MinInt32
+		for _, c := range part {
+			if cells[c].x < minX {
+				minX = cells[c].x
+			}
+			if cells[c].x > maxX {
+				maxX = cells[c].x
+			}
+		}
+		for _, c := range part {
+			cells[c] = cell{cells[c].x + offset - minX, cells[c].y}
+		}
+		offset += maxX - minX + 2
+	}
+
+	fitCells(cities, cells, width, height)
+}
+
+// route is a road seen from one of its ends, with the direction it goes towards.
+type route struct {
+	to  *City
+	dir direction
+}
+
+// directedRoads returns the roads of each city in both directions, with the directions
+// as seen from each end, sorted so that roads with a direction are walked first.
+func directedRoads(cities []*City) map[*City][]route {
+	adjacent := make(map[*City][]route, len(cities))
+	seen := make(map[[2]*City]bool)
+	add := func(from, to *City, dir direction) {
+		if seen[[2]*City{from, to}] {
+			return
+		}
+		seen[[2]*City{from, to}] = true
+		adjacent[from] = append(adjacent[from], route{to: to, dir: dir})
+	}
+
+	for _, c := range cities {
+		for _, n := range c.Neighbors {
+			dir := c.neighborMap[n]
+			add(c, n, dir)
+			add(n, c, oppositeDirectionMap[dir])
+		}
+	}
+
+	for _, routes := range adjacent {
+		sort.SliceStable(routes, func(i, j int) bool {
+			if (routes[i].dir == "") != (routes[j].dir == "") {
+				return routes[i].dir != ""
+			}
+			return routes[i].to.Name < routes[j].to.Name
+		})
+	}
+
+	return adjacent
+}
+
+// freeCell returns the target cell if it's free, or the closest free cell around it.
+func freeCell(target cell, taken map[cell]bool) cell {
+	for radius := 0; ; radius++ {
+		for dy := -radius; dy <= radius; dy++ {
+			for dx := -radius; dx <= radius; dx++ {
+				// Only check the ring at the current radius
+				if abs(dx) != radius && abs(dy) != radius {
+					continue
+				}
+
+				c := cell{target.x + dx, target.y + dy}
+				if !taken[c] {
+					return c
+				}
+			}
+		}
+	}
+}
+
+// fitCells sets the positions of the cities to the center of their cells,
+// scaling the grid to fit in the area.
+func fitCells(cities []*City, cells map[*City]cell, width int32, height int32) {
+	minX, minY := math.MaxInt32, math.MaxInt32
+	maxX, maxY := math.MinInt32, math.MinInt32
+	for _, c := range cities {
+		p := cells[c]
+		if p.x < minX {
+			minX = p.x
+		}
+		if p.x > maxX {
+			maxX = p.x
+		}
+		if p.y < minY {
+			minY = p.y
+		}
+		if p.y > maxY {
+			maxY = p.y
+		}
+	}
+
+	areaWidth := float64(width - 2*layoutMargin)
+	areaHeight := float64(height - 2*layoutMargin)
+	size := math.Min(areaWidth/math.Max(float64(maxX-minX), 1), areaHeight/math.Max(float64(maxY-minY), 1))
+
+	// Center the grid in the area
+	offsetX := layoutMargin + (areaWidth-size*float64(maxX-minX))/2
+	offsetY := layoutMargin + (areaHeight-size*float64(maxY-minY))/2
+
+	for _, c := range cities {
+		if c.pinned {
+			continue
+		}
+
+		p := cells[c]
+		c.Position = Position{
+			X: float32(offsetX + size*float64(p.x-minX)),
+			Y: float32(offsetY + size*float64(p.y-minY)),
+		}
+	}
+}
+
+// roads returns each pair of connected cities once.
+func roads(cities []*City) [][2]*City {
+	var edges [][2]*City
+	seen := make(map[[2]*City]bool)
+	for _, c := range cities {
+		for _, n := range c.Neighbors {
+			if seen[[2]*City{n, c}] || seen[[2]*City{c, n}] || n == c {
+				continue
+			}
+			seen[[2]*City{c, n}] = true
+			edges = append(edges, [2]*City{c, n})
+		}
+	}
+
+	return edges
+}
+
+// distance returns the vector from o to c and its length, which is never 0
+// so that cities in the same position still push each other.
+func distance(c, o *City) (dx, dy, d float64) {
+	dx = float64(c.Position.X - o.Position.X)
+	dy = float64(c.Position.Y - o.Position.Y)
+	d = math.Hypot(dx, dy)
+	if d < 0.01 {
+		return 0.01, 0, 0.01
+	}
+
+	return dx, dy, d
+}
+
+func clamp(v, min, max float64) float64 {
+	return math.Max(min, math.Min(max, v))
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
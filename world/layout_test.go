@@ -0,0 +1,115 @@
+package world
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLayout(t *testing.T) {
+	for _, name := range []string{LayoutRandom, LayoutForce, LayoutGrid} {
+		l, err := NewLayout(name)
+		assert.NoError(t, err)
+		assert.NotNil(t, l)
+	}
+
+	_, err := NewLayout("spiral")
+	assert.EqualError(t, err, `invalid layout: "spiral"`)
+}
+
+func TestLayouts(t *testing.T) {
+	worldDef := "A north=B east=C\nB east=D\nC north=D south=E\nD east=F\nE west=F\nF south=G\nG south=D\nH east=I"
+	layouts := []struct {
+		name   string
+		layout Layout
+	}{
+		{"random", RandomLayout{}},
+		{"force", ForceDirected{Iterations: 50}},
+		{"grid", Grid{}},
+	}
+
+	for _, test := range layouts {
+		t.Run(test.name, func(tt *testing.T) {
+			w, err := NewFromReader(strings.NewReader(worldDef), false)
+			if !assert.NoError(tt, err) {
+				return
+			}
+			test.layout.Place(w, 800, 450)
+
+			// Every city is inside the area, in a different position
+			positions := make(map[Position]string)
+			for _, city := range w.SortedCities() {
+				assert.True(tt, city.Position.X >= layoutMargin && city.Position.X <= 800-layoutMargin, "%s out of bounds: %v", city.Name, city.Position)
+				assert.True(tt, city.Position.Y >= layoutMargin && city.Position.Y <= 450-layoutMargin, "%s out of bounds: %v", city.Name, city.Position)
+
+				if other, ok := positions[city.Position]; ok {
+					tt.Errorf("%s and %s are in the same position", city.Name, other)
+				}
+				positions[city.Position] = city.Name
+			}
+		})
+	}
+}
+
+func TestGrid(t *testing.T) {
+	t.Run("follows the directions of the roads", func(tt *testing.T) {
+		w, err := NewFromReader(strings.NewReader("A north=B east=C\nC south=D\nB west=E"), false)
+		if !assert.NoError(tt, err) {
+			return
+		}
+		Grid{}.Place(w, 800, 450)
+
+		a, b, c, d, e := w.Cities["A"].Position, w.Cities["B"].Position, w.Cities["C"].Position, w.Cities["D"].Position, w.Cities["E"].Position
+		assert.Equal(tt, a.X, b.X)
+		assert.Less(tt, b.Y, a.Y)
+		assert.Equal(tt, a.Y, c.Y)
+		assert.Less(tt, a.X, c.X)
+		assert.Equal(tt, c.X, d.X)
+		assert.Less(tt, c.Y, d.Y)
+		assert.Equal(tt, b.Y, e.Y)
+		assert.Less(tt, e.X, b.X)
+	})
+
+	t.Run("pinned cities keep their position", func(tt *testing.T) {
+		w, err := NewFromBytes([]byte(`[{"name": "A", "x": 10, "y": 20, "neighbors": ["B"]}]`), false)
+		if !assert.NoError(tt, err) {
+			return
+		}
+		Grid{}.Place(w, 800, 450)
+		ForceDirected{Iterations: 10}.Place(w, 800, 450)
+
+		assert.Equal(tt, Position{X: 10, Y: 20}, w.Cities["A"].Position)
+	})
+}
+
+func TestForceDirected(t *testing.T) {
+	worldDef := "A east=B\nB east=C\nC east=D\nD east=E"
+	place := func() []Position {
+		w, err := NewFromReader(strings.NewReader(worldDef), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ForceDirected{}.Place(w, 800, 450)
+
+		var positions []Position
+		for _, city := range w.SortedCities() {
+			positions = append(positions, city.Position)
+		}
+		return positions
+	}
+
+	t.Run("same random source leads to the same layout", func(tt *testing.T) {
+		assert.Equal(tt, place(), place())
+	})
+
+	t.Run("neighbors are closer than cities at the ends of the path", func(tt *testing.T) {
+		positions := place()
+		dist := func(a, b Position) float64 {
+			_, _, d := distance(&City{Position: a}, &City{Position: b})
+			return d
+		}
+
+		assert.Less(tt, dist(positions[0], positions[1]), dist(positions[0], positions[4]))
+	})
+}
@@ -0,0 +1,232 @@
+package world
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// NewFromReader returns a new World based on a text map.
+// Each line defines a city followed by up to four roads in the format direction=City,
+// e.g. "Foo north=Bar west=Baz". Empty lines are ignored.
+func NewFromReader(r io.Reader, isDirected bool) (*World, error) {
+	world := World{
+		Cities:   make(map[string]*City),
+		directed: isDirected,
+	}
+
+	// Parse city and roads from each line
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		cityDef, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing line %d: %w", lineNumber, err)
+		}
+
+		if err := world.addCityAndRoads(cityDef); err != nil {
+			return nil, fmt.Errorf("error adding city in line %d: %w", lineNumber, err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading map: %w", err)
+	}
+
+	return &world, nil
+}
+
+// parseLine parses a single line of a text map into a city definition.
+func parseLine(line string) (*cityDefinition, error) {
+	segments := strings.Fields(line)
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("invalid input: empty string")
+	}
+
+	// The first segment is the city name, the rest are roads
+	if len(segments) > maxRoads+1 {
+		return nil, fmt.Errorf("invalid number of segments: %d", len(segments))
+	}
+
+	cityDef := cityDefinition{
+		name:        segments[0],
+		neighbors:   make([]string, 0, len(segments)-1),
+		neighborMap: make(map[string]direction, len(segments)-1),
+	}
+
+	usedDirections := make(map[direction]struct{}, len(segments)-1)
+	for _, road := range segments[1:] {
+		parts := strings.Split(road, "=")
+		if len(parts) != 2 || parts[1] == "" {
+			return nil, fmt.Errorf("invalid road definition: %q", road)
+		}
+
+		dir, err := stringToDirection(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("error converting to direction: %w", err)
+		}
+
+		// Each direction can only lead to one city
+		if _, ok := usedDirections[dir]; ok {
+			return nil, fmt.Errorf("duplicated direction: %q", dir)
+		}
+		usedDirections[dir] = struct{}{}
+
+		// Each city can only be reached by one road
+		if _, ok := cityDef.neighborMap[parts[1]]; ok {
+			return nil, fmt.Errorf("duplicated road to %q", parts[1])
+		}
+
+		cityDef.neighbors = append(cityDef.neighbors, parts[1])
+		cityDef.neighborMap[parts[1]] = dir
+	}
+
+	return &cityDef, nil
+}
+
+// jsonCityDefinition is a city as defined in a JSON map.
+// X and Y are optional, but if one of them is set the other one must be set too.
+// The attributes are optional and written next to the name.
+type jsonCityDefinition struct {
+	Name      string     `json:"name"`
+	Neighbors []jsonRoad `json:"neighbors"`
+	X         *float32   `json:"x,omitempty"`
+	Y         *float32   `json:"y,omitempty"`
+	Attributes
+}
+
+// jsonRoad is a road in a JSON map. It can be defined either as the name of the
+// neighbor city or as an object with the neighbor name, and optionally the direction
+// and the length of the road, e.g. "Bar" or {"name": "Bar", "direction": "north", "length": 120}.
+type jsonRoad struct {
+	Name      string  `json:"name"`
+	Direction string  `json:"direction,omitempty"`
+	Length    float64 `json:"length,omitempty"`
+}
+
+// MarshalJSON writes roads without a direction or a length as the name of the neighbor city.
+func (r jsonRoad) MarshalJSON() ([]byte, error) {
+	if r.Direction == "" && r.Length == 0 {
+		return json.Marshal(r.Name)
+	}
+
+	// Use a different type to avoid calling this method recursively
+	type road jsonRoad
+	return json.Marshal(road(r))
+}
+
+// UnmarshalJSON accepts both the string and the object representation of a road.
+func (r *jsonRoad) UnmarshalJSON(b []byte) error {
+	var name string
+	if err := json.Unmarshal(b, &name); err == nil {
+		*r = jsonRoad{Name: name}
+		return nil
+	}
+
+	// Use a different type to avoid calling this method recursively
+	type road jsonRoad
+	var rd road
+	if err := json.Unmarshal(b, &rd); err != nil {
+		return err
+	}
+	*r = jsonRoad(rd)
+
+	return nil
+}
+
+// parseJSONCity converts a city from a JSON map into a city definition.
+func parseJSONCity(jsonCityDef jsonCityDefinition) (*cityDefinition, error) {
+	if jsonCityDef.Name == "" {
+		return nil, fmt.Errorf("invalid input: empty city name")
+	}
+
+	cityDef := cityDefinition{
+		name:        jsonCityDef.Name,
+		neighbors:   make([]string, 0, len(jsonCityDef.Neighbors)),
+		neighborMap: make(map[string]direction, len(jsonCityDef.Neighbors)),
+	}
+
+	if (jsonCityDef.X == nil) != (jsonCityDef.Y == nil) {
+		return nil, fmt.Errorf("invalid position for %s: both x and y must be set", jsonCityDef.Name)
+	}
+	if jsonCityDef.X != nil {
+		cityDef.position = &Position{X: *jsonCityDef.X, Y: *jsonCityDef.Y}
+	}
+
+	if err := jsonCityDef.Attributes.validate(jsonCityDef.Name); err != nil {
+		return nil, err
+	}
+	cityDef.attributes = jsonCityDef.Attributes
+
+	usedDirections := make(map[direction]struct{}, len(jsonCityDef.Neighbors))
+	usedNeighbors := make(map[string]struct{}, len(jsonCityDef.Neighbors))
+	for _, road := range jsonCityDef.Neighbors {
+		if road.Name == "" {
+			return nil, fmt.Errorf("invalid road definition for %s: empty neighbor name", jsonCityDef.Name)
+		}
+
+		// Each city can only be reached by one road
+		if _, ok := usedNeighbors[road.Name]; ok {
+			return nil, fmt.Errorf("duplicated road to %q", road.Name)
+		}
+		usedNeighbors[road.Name] = struct{}{}
+
+		cityDef.neighbors = append(cityDef.neighbors, road.Name)
+
+		if road.Length < 0 {
+			return nil, fmt.Errorf("invalid length for the road from %s to %s: %g", jsonCityDef.Name, road.Name, road.Length)
+		}
+		if road.Length > 0 {
+			if cityDef.lengths == nil {
+				cityDef.lengths = make(map[string]float64)
+			}
+			cityDef.lengths[road.Name] = road.Length
+		}
+
+		// Roads without direction are allowed in JSON maps
+		if road.Direction == "" {
+			continue
+		}
+
+		dir, err := stringToDirection(road.Direction)
+		if err != nil {
+			return nil, fmt.Errorf("error converting to direction: %w", err)
+		}
+
+		// Each direction can only lead to one city
+		if _, ok := usedDirections[dir]; ok {
+			return nil, fmt.Errorf("duplicated direction: %q", dir)
+		}
+		usedDirections[dir] = struct{}{}
+
+		cityDef.neighborMap[road.Name] = dir
+	}
+
+	return &cityDef, nil
+}
+
+// Format is the format of a map file.
+type Format string
+
+const (
+	// FormatText is the line-based map format, e.g. "Foo north=Bar west=Baz".
+	FormatText Format = "text"
+	// FormatJSON is the map format with an array of cities and their neighbors.
+	FormatJSON Format = "json"
+)
+
+// FormatFromPath returns FormatJSON for files with a .json extension and FormatText otherwise.
+func FormatFromPath(path string) Format {
+	if filepath.Ext(path) == ".json" {
+		return FormatJSON
+	}
+
+	return FormatText
+}
@@ -1,6 +1,8 @@
 package world
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -95,3 +97,258 @@ func TestParseLine(t *testing.T) {
 		})
 	}
 }
+
+func TestNewFromReader(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{
+			"valid map",
+			"Gerli south=DockSud\n\nDockSud north=Gerli\n",
+			"",
+		},
+		{
+			"invalid direction in second line",
+			"Gerli south=DockSud\nDockSud southeast=Gerli",
+			`error parsing line 2: error converting to direction: cannot convert string "southeast" to direction type`,
+		},
+		{
+			"empty lines are counted",
+			"Gerli south=DockSud\n\n\nDockSud north=Gerli=Lanús",
+			`error parsing line 4: invalid road definition: "north=Gerli=Lanús"`,
+		},
+		{
+			"duplicated direction",
+			"Gerli south=DockSud south=Lanús",
+			`error parsing line 1: duplicated direction: "south"`,
+		},
+		{
+			"same direction in different lines",
+			"Gerli south=DockSud\nLanús south=DockSud",
+			"error adding city in line 2: inconsistent directions: both Gerli and Lanús are north of DockSud",
+		},
+		{
+			"duplicated road",
+			"Gerli north=DockSud south=DockSud",
+			`error parsing line 1: duplicated road to "DockSud"`,
+		},
+		{
+			"inconsistent directions",
+			"Gerli south=DockSud\nDockSud east=Gerli",
+			"error adding city in line 2: inconsistent directions: Gerli is both north and east of DockSud",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			w, err := NewFromReader(strings.NewReader(test.input), false)
+			if test.err != "" {
+				assert.EqualError(tt, err, test.err)
+				return
+			}
+
+			assert.NoError(tt, err)
+			assert.NotNil(tt, w)
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	input := "Avellaneda south=Gerli\nGerli north=Avellaneda east=Lanús\nLanús west=Gerli\n"
+	for _, directed := range []bool{true, false} {
+		t.Run(fmt.Sprintf("directed %t", directed), func(tt *testing.T) {
+			w, err := NewFromReader(strings.NewReader(input), directed)
+			if !assert.NoError(tt, err) {
+				return
+			}
+
+			reloaded, err := NewFromReader(strings.NewReader(w.String()), directed)
+			if !assert.NoError(tt, err) {
+				return
+			}
+
+			assert.Equal(tt, input, w.String())
+			assert.Equal(tt, w.String(), reloaded.String())
+		})
+	}
+}
+
+func TestNewFromBytes(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		directed bool
+		check    func(tt *testing.T, w *World)
+		err      string
+	}{
+		{
+			"neighbors as names",
+			`[{"name": "Gerli", "neighbors": ["Lanús", "Avellaneda"]}]`,
+			false,
+			func(tt *testing.T, w *World) {
+				assert.Equal(tt, 3, len(w.Cities))
+				assert.Equal(tt, 2, len(w.Cities["Gerli"].Neighbors))
+			},
+			"",
+		},
+		{
+			"neighbors with directions and pinned position",
+			`[{"name": "Gerli", "x": 100, "y": 200, "neighbors": [{"name": "Lanús", "direction": "east"}, "Avellaneda"]}]`,
+			false,
+			func(tt *testing.T, w *World) {
+				gerli, lanus := w.Cities["Gerli"], w.Cities["Lanús"]
+				assert.Equal(tt, east, gerli.neighborMap[lanus])
+				assert.Equal(tt, west, lanus.neighborMap[gerli])
+				assert.Equal(tt, Position{X: 100, Y: 200}, gerli.Position)
+
+				// Pinned cities are not moved
+				w.Scatter(800, 450)
+				assert.Equal(tt, Position{X: 100, Y: 200}, gerli.Position)
+			},
+			"",
+		},
+		{
+			"direction declared on the second definition",
+			`[{"name": "Gerli", "neighbors": ["Lanús"]}, {"name": "Lanús", "neighbors": [{"name": "Gerli", "direction": "west"}]}]`,
+			false,
+			func(tt *testing.T, w *World) {
+				gerli, lanus := w.Cities["Gerli"], w.Cities["Lanús"]
+				assert.Equal(tt, east, gerli.neighborMap[lanus])
+				assert.Equal(tt, west, lanus.neighborMap[gerli])
+			},
+			"",
+		},
+		{
+			"consistent directions",
+			`[{"name": "Gerli", "neighbors": [{"name": "Lanús", "direction": "east"}]}, {"name": "Lanús", "neighbors": [{"name": "Gerli", "direction": "west"}]}]`,
+			false,
+			nil,
+			"",
+		},
+		{
+			"inconsistent directions, non-directed",
+			`[{"name": "Gerli", "neighbors": [{"name": "Lanús", "direction": "east"}]}, {"name": "Lanús", "neighbors": [{"name": "Gerli", "direction": "north"}]}]`,
+			false,
+			nil,
+			"error adding city 1: inconsistent directions: Gerli is both west and north of Lanús",
+		},
+		{
+			"inconsistent directions, directed",
+			`[{"name": "Gerli", "neighbors": [{"name": "Lanús", "direction": "east"}]}, {"name": "Lanús", "neighbors": [{"name": "Gerli", "direction": "north"}]}]`,
+			true,
+			nil,
+			"",
+		},
+		{
+			"too many roads in different cities",
+			`[{"name": "A", "neighbors": ["X"]}, {"name": "B", "neighbors": ["X"]}, {"name": "C", "neighbors": ["X"]}, {"name": "D", "neighbors": ["X"]}, {"name": "E", "neighbors": ["X"]}]`,
+			false,
+			nil,
+			"error adding city 4: too many roads: X can't have more than 4",
+		},
+		{
+			"too many roads in different cities, directed",
+			`[{"name": "A", "neighbors": ["X"]}, {"name": "B", "neighbors": ["X"]}, {"name": "C", "neighbors": ["X"]}, {"name": "D", "neighbors": ["X"]}, {"name": "E", "neighbors": ["X"]}]`,
+			true,
+			nil,
+			"",
+		},
+		{
+			"direction set by the second definition",
+			`[{"name": "Gerli", "neighbors": [{"name": "Lanús", "direction": "east"}, "Bernal"]}, {"name": "Bernal", "neighbors": [{"name": "Gerli", "direction": "west"}]}]`,
+			false,
+			nil,
+			"error adding city 1: inconsistent directions: both Lanús and Bernal are east of Gerli",
+		},
+		{
+			"duplicated road",
+			`[{"name": "Gerli", "neighbors": ["Lanús", {"name": "Lanús", "direction": "east", "length": 3}]}]`,
+			false,
+			nil,
+			`error parsing city 0: duplicated road to "Lanús"`,
+		},
+		{
+			"invalid direction",
+			`[{"name": "Gerli", "neighbors": [{"name": "Lanús", "direction": "up"}]}]`,
+			false,
+			nil,
+			`error parsing city 0: error converting to direction: cannot convert string "up" to direction type`,
+		},
+		{
+			"city attributes",
+			`[{"name": "Gerli", "population": 1000, "defense": 0.25, "hit_points": 3, "value": 2.5, "neighbors": ["Lanús"]}]`,
+			false,
+			func(tt *testing.T, w *World) {
+				assert.Equal(tt, Attributes{Population: 1000, Defense: 0.25, HitPoints: 3, Value: 2.5}, w.Cities["Gerli"].Attributes)
+				assert.Equal(tt, 3, w.Cities["Gerli"].Attributes.MaxHitPoints())
+				assert.Equal(tt, Attributes{}, w.Cities["Lanús"].Attributes)
+				assert.Equal(tt, 1, w.Cities["Lanús"].Attributes.MaxHitPoints())
+			},
+			"",
+		},
+		{
+			"attributes declared after the city is a neighbor",
+			`[{"name": "Gerli", "neighbors": ["Lanús"]}, {"name": "Lanús", "population": 500, "neighbors": []}]`,
+			false,
+			func(tt *testing.T, w *World) {
+				assert.Equal(tt, 500, w.Cities["Lanús"].Attributes.Population)
+			},
+			"",
+		},
+		{
+			"negative population",
+			`[{"name": "Gerli", "population": -1}]`,
+			false,
+			nil,
+			"error parsing city 0: invalid population for Gerli: -1",
+		},
+		{
+			"defense out of range",
+			`[{"name": "Gerli", "defense": 1.5}]`,
+			false,
+			nil,
+			"error parsing city 0: invalid defense for Gerli: must be between 0 and 1, got 1.5",
+		},
+		{
+			"negative hit points",
+			`[{"name": "Gerli", "hit_points": -2}]`,
+			false,
+			nil,
+			"error parsing city 0: invalid hit points for Gerli: -2",
+		},
+		{
+			"negative value",
+			`[{"name": "Gerli", "value": -1}]`,
+			false,
+			nil,
+			"error parsing city 0: invalid value for Gerli: -1",
+		},
+		{
+			"only one coordinate",
+			`[{"name": "Gerli", "x": 100}]`,
+			false,
+			nil,
+			"error parsing city 0: invalid position for Gerli: both x and y must be set",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			w, err := NewFromBytes([]byte(test.input), test.directed)
+			if test.err != "" {
+				assert.EqualError(tt, err, test.err)
+				return
+			}
+
+			if !assert.NoError(tt, err) {
+				return
+			}
+
+			if test.check != nil {
+				test.check(tt, w)
+			}
+		})
+	}
+}
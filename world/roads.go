@@ -0,0 +1,372 @@
+package world
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Road is a road from one city to another. Unless the World is directed,
+// roads go both ways and changing a road changes both directions.
+type Road struct {
+	From *City
+	To   *City
+}
+
+// BlockedRoad is a road closed until the World reaches a given turn.
+type BlockedRoad struct {
+	Road
+	// Until is the turn of the World in which Tick opens the road again
+	Until int
+	// edits are the roads removed from the cities while the road is blocked, one for each direction
+	edits []roadEdit
+}
+
+// roadEdit is a road removed from a city, with everything needed to put it back.
+type roadEdit struct {
+	from, to *City
+	// index is the position of the road in the neighbors of from
+	index  int
+	dir    direction
+	length float64
+}
+
+type changeKind int
+
+const (
+	changeDestroyCity changeKind = iota
+	changeDestroyRoads
+	changeBlockRoads
+	changeUnblockRoad
+	changeTick
+)
+
+// Change records what an operation on the World changed, so that it can be undone
+// with Undo and made again with Redo, e.g. to step back and forth in a simulation.
+type Change struct {
+	kind     changeKind
+	deletion Deletion
+	// roads are the roads destroyed, blocked or opened again, in order
+	roads []Road
+	// removed are the roads removed from the cities, in order
+	removed []roadEdit
+	// blocked are the roads blocked by the change, and opened the blocks it ended
+	blocked []*BlockedRoad
+	opened  []openedRoad
+}
+
+// openedRoad is a block that ended, along with its position in the blocked roads of the World
+// and the roads added back, which are none if one of its cities was destroyed meanwhile.
+type openedRoad struct {
+	block *BlockedRoad
+	index int
+	added []roadEdit
+}
+
+// Roads returns the roads destroyed, blocked or opened again by the change.
+func (c Change) Roads() []Road {
+	return c.roads
+}
+
+// Turn returns the amount of times Tick was called.
+func (w *World) Turn() int {
+	return w.turn
+}
+
+// BlockedRoads returns the roads currently blocked, in the order they were blocked.
+func (w *World) BlockedRoads() []BlockedRoad {
+	blocked := make([]BlockedRoad, len(w.blocked))
+	for i, b := range w.blocked {
+		blocked[i] = *b
+	}
+
+	return blocked
+}
+
+// HasBlockedRoads reports whether a blocked road that will open again leaves the city,
+// so the city will have roads again later.
+func (w *World) HasBlockedRoads(city *City) bool {
+	for _, b := range w.blocked {
+		if (b.From == city || (!w.directed && b.To == city)) && w.opens(b) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DestroyCity removes a city and all its roads like DeleteCityAndRoads, returning a Change instead.
+func (w *World) DestroyCity(city *City) Change {
+	return Change{kind: changeDestroyCity, deletion: w.DeleteCityAndRoads(city)}
+}
+
+// DestroyRoad removes the road from one city to another for good.
+func (w *World) DestroyRoad(from, to *City) (Change, error) {
+	c := Change{kind: changeDestroyRoads}
+	if err := w.destroyRoad(&c, Road{From: from, To: to}); err != nil {
+		return Change{}, err
+	}
+
+	return c, nil
+}
+
+// BlockRoad closes the road from one city to another for the given amount of turns, after which
+// Tick opens it again, unless one of its cities is destroyed meanwhile, which forgets the block.
+func (w *World) BlockRoad(from, to *City, turns int) (Change, error) {
+	if turns < 1 {
+		return Change{}, fmt.Errorf("invalid amount of turns: %d", turns)
+	}
+
+	c := Change{kind: changeBlockRoads}
+	if err := w.blockRoad(&c, Road{From: from, To: to}, w.turn+turns); err != nil {
+		return Change{}, err
+	}
+
+	return c, nil
+}
+
+// UnblockRoad opens a blocked road before its time.
+func (w *World) UnblockRoad(from, to *City) (Change, error) {
+	for i, b := range w.blocked {
+		if (b.From == from && b.To == to) || (!w.directed && b.From == to && b.To == from) {
+			c := Change{kind: changeUnblockRoad}
+			w.open(&c, i)
+			return c, nil
+		}
+	}
+
+	return Change{}, fmt.Errorf("invalid road: the road from %s to %s isn't blocked", from.Name, to.Name)
+}
+
+// DamageCity takes the given amount of roads from a city, picked at random with rng, or all of them
+// if it has fewer. The roads are destroyed, or blocked for the given amount of turns if it's not 0.
+// Only roads leaving the city are taken in directed Worlds. The World's random source is used if rng is nil.
+func (w *World) DamageCity(city *City, roads, turns int, rng *rand.Rand) (Change, error) {
+	if roads < 0 {
+		return Change{}, fmt.Errorf("invalid amount of roads: %d", roads)
+	}
+	if turns < 0 {
+		return Change{}, fmt.Errorf("invalid amount of turns: %d", turns)
+	}
+	if w.Cities[city.Name] != city {
+		return Change{}, fmt.Errorf("invalid city: %s was destroyed", city.Name)
+	}
+
+	picked := append([]*City(nil), city.Neighbors...)
+	if roads < len(picked) {
+		if rng == nil {
+			rng = w.random()
+		}
+
+		// Shuffle only the roads taken, so no more values than needed are drawn
+		for i := 0; i < roads; i++ {
+			j := i + rng.Intn(len(picked)-i)
+			picked[i], picked[j] = picked[j], picked[i]
+		}
+		picked = picked[:roads]
+	}
+
+	c := Change{kind: changeDestroyRoads}
+	if turns > 0 {
+		c.kind = changeBlockRoads
+	}
+	for _, n := range picked {
+		r := Road{From: city, To: n}
+		var err error
+		if turns > 0 {
+			err = w.blockRoad(&c, r, w.turn+turns)
+		} else {
+			err = w.destroyRoad(&c, r)
+		}
+		if err != nil {
+			return Change{}, err
+		}
+	}
+
+	return c, nil
+}
+
+// Tick moves the World one turn forward, opening the blocked roads whose time is up.
+func (w *World) Tick() Change {
+	w.turn++
+	c := Change{kind: changeTick}
+	for i := 0; i < len(w.blocked); {
+		if w.blocked[i].Until <= w.turn {
+			w.open(&c, i)
+			continue
+		}
+		i++
+	}
+
+	return c
+}
+
+// Undo undoes a change. Changes must be undone in the opposite order they were made, starting from the last one.
+func (w *World) Undo(c Change) error {
+	if c.kind == changeDestroyCity {
+		return w.RestoreCity(c.deletion)
+	}
+
+	for i := len(c.opened) - 1; i >= 0; i-- {
+		o := c.opened[i]
+		for j := len(o.added) - 1; j >= 0; j-- {
+			o.added[j].from.removeNeighbor(o.added[j].to)
+		}
+
+		blocked := make([]*BlockedRoad, 0, len(w.blocked)+1)
+		blocked = append(blocked, w.blocked[:o.index]...)
+		blocked = append(blocked, o.block)
+		w.blocked = append(blocked, w.blocked[o.index:]...)
+	}
+
+	for i := len(c.blocked) - 1; i >= 0; i-- {
+		w.removeBlock(c.blocked[i])
+	}
+	for i := len(c.removed) - 1; i >= 0; i-- {
+		c.removed[i].from.addNeighbor(c.removed[i])
+	}
+
+	if c.kind == changeTick {
+		w.turn--
+	}
+
+	return nil
+}
+
+// Redo makes an undone change again, and returns the Change to undo it. Roads picked
+// at random are the same ones, so the World ends up exactly like it was before Undo.
+func (w *World) Redo(c Change) (Change, error) {
+	switch c.kind {
+	case changeDestroyCity:
+		return w.DestroyCity(c.deletion.city), nil
+	case changeTick:
+		return w.Tick(), nil
+	case changeUnblockRoad:
+		b := c.opened[0].block
+		return w.UnblockRoad(b.From, b.To)
+	}
+
+	redone := Change{kind: c.kind}
+	for i, r := range c.roads {
+		var err error
+		if c.kind == changeBlockRoads {
+			err = w.blockRoad(&redone, r, c.blocked[i].Until)
+		} else {
+			err = w.destroyRoad(&redone, r)
+		}
+		if err != nil {
+			return Change{}, err
+		}
+	}
+
+	return redone, nil
+}
+
+func (w *World) destroyRoad(c *Change, r Road) error {
+	edits, err := w.removeRoad(r)
+	if err != nil {
+		return err
+	}
+
+	c.roads = append(c.roads, r)
+	c.removed = append(c.removed, edits...)
+	return nil
+}
+
+func (w *World) blockRoad(c *Change, r Road, until int) error {
+	edits, err := w.removeRoad(r)
+	if err != nil {
+		return err
+	}
+
+	b := &BlockedRoad{Road: r, Until: until, edits: edits}
+	w.blocked = append(w.blocked, b)
+	c.roads = append(c.roads, r)
+	c.removed = append(c.removed, edits...)
+	c.blocked = append(c.blocked, b)
+	return nil
+}
+
+// removeRoad removes a road from its cities, both ways unless the World is directed.
+func (w *World) removeRoad(r Road) ([]roadEdit, error) {
+	if w.Cities[r.From.Name] != r.From {
+		return nil, fmt.Errorf("invalid road: %s was destroyed", r.From.Name)
+	}
+	if _, ok := r.From.neighborMap[r.To]; !ok {
+		return nil, fmt.Errorf("invalid road: there's no road from %s to %s", r.From.Name, r.To.Name)
+	}
+
+	edits := []roadEdit{r.From.removeNeighbor(r.To)}
+	if _, ok := r.To.neighborMap[r.From]; ok && !w.directed {
+		edits = append(edits, r.To.removeNeighbor(r.From))
+	}
+
+	return edits, nil
+}
+
+// open ends the i-th block, putting its roads back at the end of the neighbors of
+// their cities, unless one of them was destroyed.
+func (w *World) open(c *Change, i int) {
+	b := w.blocked[i]
+	w.removeBlock(b)
+	o := openedRoad{block: b, index: i}
+
+	if w.opens(b) {
+		for _, e := range b.edits {
+			e.index = len(e.from.Neighbors)
+			e.from.addNeighbor(e)
+			o.added = append(o.added, e)
+		}
+		c.roads = append(c.roads, b.Road)
+	}
+	c.opened = append(c.opened, o)
+}
+
+// opens reports whether a blocked road opens again once its turns are over, which
+// doesn't happen if one of its cities was destroyed.
+func (w *World) opens(b *BlockedRoad) bool {
+	return w.Cities[b.From.Name] == b.From && w.Cities[b.To.Name] == b.To
+}
+
+func (w *World) removeBlock(b *BlockedRoad) {
+	blocked := make([]*BlockedRoad, 0, len(w.blocked))
+	for _, other := range w.blocked {
+		if other != b {
+			blocked = append(blocked, other)
+		}
+	}
+	w.blocked = blocked
+}
+
+// removeNeighbor removes the road to a neighbor. Neighbors are copied instead of changed
+// in place, since a Deletion might hold the previous ones.
+func (c *City) removeNeighbor(to *City) roadEdit {
+	e := roadEdit{from: c, to: to, dir: c.neighborMap[to], length: c.lengths[to]}
+	neighbors := make([]*City, 0, len(c.Neighbors))
+	for i, n := range c.Neighbors {
+		if n == to {
+			e.index = i
+			continue
+		}
+		neighbors = append(neighbors, n)
+	}
+
+	c.Neighbors = neighbors
+	delete(c.neighborMap, to)
+	delete(c.lengths, to)
+	return e
+}
+
+// addNeighbor puts back a road removed by removeNeighbor, in the same position if possible.
+func (c *City) addNeighbor(e roadEdit) {
+	index := e.index
+	if index > len(c.Neighbors) {
+		index = len(c.Neighbors)
+	}
+
+	neighbors := make([]*City, 0, len(c.Neighbors)+1)
+	neighbors = append(neighbors, c.Neighbors[:index]...)
+	neighbors = append(neighbors, e.to)
+	c.Neighbors = append(neighbors, c.Neighbors[index:]...)
+	c.neighborMap[e.to] = e.dir
+	c.setLength(e.to, e.length)
+}
@@ -0,0 +1,358 @@
+package world
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const roadsMap = "A north=B east=C\nB east=D\nC north=D south=E\nD east=F\nE west=F"
+
+func TestDestroyRoad(t *testing.T) {
+	tests := []struct {
+		name     string
+		directed bool
+		from, to string
+		expected string
+		err      string
+	}{
+		{
+			"both ways",
+			false,
+			"A", "B",
+			"A east=C\nB east=D\nC west=A north=D south=E\nD west=B south=C east=F\nE north=C west=F\nF west=D east=E\n",
+			"",
+		},
+		{
+			"one way in directed worlds",
+			true,
+			"C", "D",
+			"A north=B east=C\nB east=D\nC south=E\nD east=F\nE west=F\nF\n",
+			"",
+		},
+		{
+			"no road",
+			false,
+			"A", "F",
+			"",
+			"invalid road: there's no road from A to F",
+		},
+		{
+			"no road back in directed worlds",
+			true,
+			"B", "A",
+			"",
+			"invalid road: there's no road from B to A",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			w, err := NewFromReader(strings.NewReader(roadsMap), test.directed)
+			if !assert.NoError(tt, err) {
+				return
+			}
+
+			c, err := w.DestroyRoad(w.Cities[test.from], w.Cities[test.to])
+			if test.err != "" {
+				assert.EqualError(tt, err, test.err)
+				return
+			}
+			if !assert.NoError(tt, err) {
+				return
+			}
+
+			assert.Equal(tt, test.expected, w.String())
+			assert.Equal(tt, []Road{{From: w.Cities[test.from], To: w.Cities[test.to]}}, c.Roads())
+		})
+	}
+}
+
+func TestDestroyRoadOfDestroyedCity(t *testing.T) {
+	w, err := NewFromReader(strings.NewReader(roadsMap), false)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	a := w.Cities["A"]
+	w.DestroyCity(a)
+	_, err = w.DestroyRoad(a, w.Cities["B"])
+	assert.EqualError(t, err, "invalid road: A was destroyed")
+	_, err = w.DamageCity(a, 1, 0, nil)
+	assert.EqualError(t, err, "invalid city: A was destroyed")
+}
+
+func TestBlockRoad(t *testing.T) {
+	for _, directed := range []bool{false, true} {
+		t.Run(fmt.Sprintf("directed %t", directed), func(tt *testing.T) {
+			w, err := NewFromReader(strings.NewReader(roadsMap), directed)
+			if !assert.NoError(tt, err) {
+				return
+			}
+			a, b, c := w.Cities["A"], w.Cities["B"], w.Cities["C"]
+
+			_, err = w.BlockRoad(a, b, 0)
+			assert.EqualError(tt, err, "invalid amount of turns: 0")
+
+			_, err = w.BlockRoad(a, b, 2)
+			if !assert.NoError(tt, err) {
+				return
+			}
+			assert.Equal(tt, []*City{c}, a.Neighbors)
+			assert.Equal(tt, []BlockedRoad{{Road: Road{From: a, To: b}, Until: 2}}, withoutEdits(w.BlockedRoads()))
+			assert.True(tt, w.HasBlockedRoads(a))
+			assert.Equal(tt, !directed, w.HasBlockedRoads(b))
+			assert.False(tt, w.HasBlockedRoads(c))
+
+			// The road opens again once its turns are over
+			assert.Empty(tt, w.Tick().Roads())
+			assert.Equal(tt, []*City{c}, a.Neighbors)
+			assert.Equal(tt, []Road{{From: a, To: b}}, w.Tick().Roads())
+			assert.Equal(tt, 2, w.Turn())
+			assert.Empty(tt, w.BlockedRoads())
+			assert.Equal(tt, []*City{c, b}, a.Neighbors)
+			assert.Equal(tt, direction("north"), a.neighborMap[b])
+			if !directed {
+				assert.Equal(tt, []*City{w.Cities["D"], a}, b.Neighbors)
+				assert.Equal(tt, direction("south"), b.neighborMap[a])
+			}
+		})
+	}
+}
+
+func TestBlockedRoadOfDestroyedCity(t *testing.T) {
+	w, err := NewFromReader(strings.NewReader(roadsMap), false)
+	if !assert.NoError(t, err) {
+		return
+	}
+	a, b := w.Cities["A"], w.Cities["B"]
+
+	_, err = w.BlockRoad(a, b, 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+	// The block is forgotten with the city, and comes back if the city is restored
+	destroyed := w.DestroyCity(b)
+	assert.False(t, w.HasBlockedRoads(a))
+	assert.Empty(t, w.BlockedRoads())
+	if !assert.NoError(t, w.Undo(destroyed)) {
+		return
+	}
+	assert.Equal(t, []BlockedRoad{{Road: Road{From: a, To: b}, Until: 1}}, withoutEdits(w.BlockedRoads()))
+	w.DestroyCity(b)
+
+	// The block ends, but the road doesn't come back
+	assert.Empty(t, w.Tick().Roads())
+	assert.Empty(t, w.BlockedRoads())
+	assert.Equal(t, []*City{w.Cities["C"]}, a.Neighbors)
+}
+
+func TestUnblockRoad(t *testing.T) {
+	w, err := NewFromReader(strings.NewReader(roadsMap), false)
+	if !assert.NoError(t, err) {
+		return
+	}
+	a, b, c := w.Cities["A"], w.Cities["B"], w.Cities["C"]
+
+	_, err = w.UnblockRoad(a, b)
+	assert.EqualError(t, err, "invalid road: the road from A to B isn't blocked")
+
+	_, err = w.BlockRoad(a, b, 5)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Roads of undirected worlds can be opened from either end
+	change, err := w.UnblockRoad(b, a)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []Road{{From: a, To: b}}, change.Roads())
+	assert.Equal(t, []*City{c, b}, a.Neighbors)
+	assert.Empty(t, w.BlockedRoads())
+}
+
+func TestDamageCity(t *testing.T) {
+	tests := []struct {
+		name     string
+		directed bool
+		city     string
+		roads    int
+		turns    int
+		expected []string
+		blocked  int
+		err      string
+	}{
+		{
+			"some roads",
+			false,
+			"C", 2, 0,
+			[]string{"D"},
+			0,
+			"",
+		},
+		{
+			"more roads than the city has",
+			false,
+			"C", 5, 0,
+			[]string{},
+			0,
+			"",
+		},
+		{
+			"no roads",
+			false,
+			"C", 0, 0,
+			[]string{"A", "D", "E"},
+			0,
+			"",
+		},
+		{
+			"blocked roads",
+			false,
+			"C", 3, 2,
+			[]string{},
+			3,
+			"",
+		},
+		{
+			"only roads leaving the city in directed worlds",
+			true,
+			"C", 5, 0,
+			[]string{},
+			0,
+			"",
+		},
+		{
+			"negative roads",
+			false,
+			"C", -1, 0,
+			nil,
+			0,
+			"invalid amount of roads: -1",
+		},
+		{
+			"negative turns",
+			false,
+			"C", 1, -1,
+			nil,
+			0,
+			"invalid amount of turns: -1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			w, err := NewFromReader(strings.NewReader(roadsMap), test.directed)
+			if !assert.NoError(tt, err) {
+				return
+			}
+			city := w.Cities[test.city]
+			before := len(city.Neighbors)
+
+			c, err := w.DamageCity(city, test.roads, test.turns, rand.New(rand.NewSource(1)))
+			if test.err != "" {
+				assert.EqualError(tt, err, test.err)
+				return
+			}
+			if !assert.NoError(tt, err) {
+				return
+			}
+
+			assert.Equal(tt, test.expected, names(city.Neighbors))
+			assert.Len(tt, c.Roads(), before-len(city.Neighbors))
+			assert.Len(tt, w.BlockedRoads(), test.blocked)
+			for _, r := range c.Roads() {
+				assert.Equal(tt, city, r.From)
+				_, ok := r.To.neighborMap[city]
+				assert.False(tt, ok && !test.directed, "the road from %s to %s is still there", r.To.Name, city.Name)
+			}
+		})
+	}
+}
+
+func TestUndoRedo(t *testing.T) {
+	for _, directed := range []bool{false, true} {
+		t.Run(fmt.Sprintf("directed %t", directed), func(tt *testing.T) {
+			w, err := NewFromReader(strings.NewReader(roadsMap), directed)
+			if !assert.NoError(tt, err) {
+				return
+			}
+			a, b, c, d := w.Cities["A"], w.Cities["B"], w.Cities["C"], w.Cities["D"]
+
+			var changes []Change
+			var snapshots []Snapshot
+			do := func(change Change, err error) {
+				if assert.NoError(tt, err) {
+					snapshots = append(snapshots, w.Snapshot())
+					changes = append(changes, change)
+				}
+			}
+			snapshots = append(snapshots, w.Snapshot())
+			do(w.BlockRoad(a, b, 1))
+			do(w.DamageCity(c, 2, 3, rand.New(rand.NewSource(1))))
+			do(w.DestroyRoad(b, d))
+			do(w.Tick(), nil)
+			do(w.DestroyCity(d), nil)
+			do(w.Tick(), nil)
+			do(w.UnblockRoad(c, w.Cities["E"]))
+			do(w.Tick(), nil)
+			do(w.Tick(), nil)
+			if !assert.Len(tt, changes, 9) {
+				return
+			}
+
+			// Undoing every change goes through the same states backwards
+			for i := len(changes) - 1; i >= 0; i-- {
+				assert.NoError(tt, w.Undo(changes[i]))
+				assert.Equal(tt, snapshots[i], w.Snapshot(), "undoing change %d", i)
+			}
+
+			// And redoing them goes through them forwards again
+			for i, change := range changes {
+				redone, err := w.Redo(change)
+				if !assert.NoError(tt, err) {
+					return
+				}
+				changes[i] = redone
+				assert.Equal(tt, snapshots[i+1], w.Snapshot(), "redoing change %d", i)
+			}
+		})
+	}
+}
+
+func TestBlockedRoadsSnapshot(t *testing.T) {
+	w, err := NewFromBytes([]byte(`[{"name": "A", "neighbors": [{"name": "B", "direction": "north", "length": 3}, "C"]}]`), false)
+	if !assert.NoError(t, err) {
+		return
+	}
+	w.Tick()
+	_, err = w.BlockRoad(w.Cities["A"], w.Cities["B"], 2)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	restored, err := FromSnapshot(w.Snapshot())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, w.Snapshot(), restored.Snapshot())
+	assert.Equal(t, 1, restored.Turn())
+
+	// The road comes back just like it was
+	restored.Tick()
+	assert.Equal(t, []Road{{From: restored.Cities["A"], To: restored.Cities["B"]}}, restored.Tick().Roads())
+	assert.Equal(t, direction("north"), restored.Cities["A"].neighborMap[restored.Cities["B"]])
+	assert.Equal(t, direction("south"), restored.Cities["B"].neighborMap[restored.Cities["A"]])
+	assert.Equal(t, 3.0, restored.Cities["B"].Length(restored.Cities["A"]))
+}
+
+func withoutEdits(blocked []BlockedRoad) []BlockedRoad {
+	for i := range blocked {
+		blocked[i].edits = nil
+	}
+	return blocked
+}
@@ -0,0 +1,199 @@
+package world
+
+import "fmt"
+
+// Snapshot is the complete state of a World, including its destroyed cities.
+// Unlike the map formats, roads keep the order in which cities list them, which
+// random decisions depend on, so a restored World behaves exactly like the saved one.
+type Snapshot struct {
+	Directed bool                 `json:"directed"`
+	Cities   []jsonCityDefinition `json:"cities"`
+	// Destroyed are the destroyed cities, in the order they were destroyed,
+	// with the roads they had when that happened
+	Destroyed []jsonCityDefinition `json:"destroyed,omitempty"`
+	Turn      int                  `json:"turn,omitempty"`
+	// Blocked are the blocked roads, in the order they were blocked
+	Blocked []jsonBlockedRoad `json:"blocked,omitempty"`
+}
+
+type jsonBlockedRoad struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Until int    `json:"until"`
+	// Roads are the roads removed from the cities while blocked, one for each direction
+	Roads []jsonRemovedRoad `json:"roads"`
+}
+
+type jsonRemovedRoad struct {
+	From      string  `json:"from"`
+	To        string  `json:"to"`
+	Direction string  `json:"direction,omitempty"`
+	Length    float64 `json:"length,omitempty"`
+}
+
+// Snapshot returns the current state of the World. Only the positions of pinned cities are saved.
+func (w *World) Snapshot() Snapshot {
+	s := Snapshot{
+		Directed:  w.directed,
+		Cities:    make([]jsonCityDefinition, 0, len(w.Cities)),
+		Destroyed: make([]jsonCityDefinition, 0, len(w.DestroyedCities)),
+	}
+	for _, city := range w.SortedCities() {
+		s.Cities = append(s.Cities, snapshotCity(city))
+	}
+	for _, city := range w.DestroyedCities {
+		s.Destroyed = append(s.Destroyed, snapshotCity(city))
+	}
+
+	s.Turn = w.turn
+	for _, b := range w.blocked {
+		block := jsonBlockedRoad{From: b.From.Name, To: b.To.Name, Until: b.Until, Roads: make([]jsonRemovedRoad, 0, len(b.edits))}
+		for _, e := range b.edits {
+			block.Roads = append(block.Roads, jsonRemovedRoad{From: e.from.Name, To: e.to.Name, Direction: string(e.dir), Length: e.length})
+		}
+		s.Blocked = append(s.Blocked, block)
+	}
+
+	return s
+}
+
+func snapshotCity(city *City) jsonCityDefinition {
+	def := jsonCityDefinition{
+		Name:       city.Name,
+		Neighbors:  make([]jsonRoad, 0, len(city.Neighbors)),
+		Attributes: city.Attributes,
+	}
+	if city.pinned {
+		x, y := city.Position.X, city.Position.Y
+		def.X, def.Y = &x, &y
+	}
+
+	for _, n := range city.Neighbors {
+		def.Neighbors = append(def.Neighbors, jsonRoad{Name: n.Name, Direction: string(city.neighborMap[n]), Length: city.lengths[n]})
+	}
+
+	return def
+}
+
+// FromSnapshot returns a new World with the state saved in a Snapshot.
+func FromSnapshot(s Snapshot) (*World, error) {
+	w := World{
+		Cities:          make(map[string]*City, len(s.Cities)),
+		DestroyedCities: make([]*City, 0, len(s.Destroyed)),
+		directed:        s.Directed,
+	}
+
+	// Create every city first, so roads can lead to any of them
+	all := make(map[string]*City, len(s.Cities)+len(s.Destroyed))
+	defs := make(map[*City]jsonCityDefinition, len(s.Cities)+len(s.Destroyed))
+	for i, def := range append(append([]jsonCityDefinition{}, s.Cities...), s.Destroyed...) {
+		if def.Name == "" {
+			return nil, fmt.Errorf("invalid snapshot: empty name for city %d", i)
+		}
+		if _, ok := all[def.Name]; ok {
+			return nil, fmt.Errorf("invalid snapshot: duplicated city %q", def.Name)
+		}
+		if (def.X == nil) != (def.Y == nil) {
+			return nil, fmt.Errorf("invalid position for %s: both x and y must be set", def.Name)
+		}
+		if err := def.Attributes.validate(def.Name); err != nil {
+			return nil, err
+		}
+
+		city := &City{
+			Name:        def.Name,
+			Neighbors:   make([]*City, 0, len(def.Neighbors)),
+			neighborMap: make(map[*City]direction, maxRoads),
+			Attributes:  def.Attributes,
+		}
+		if def.X != nil {
+			city.Position = Position{X: *def.X, Y: *def.Y}
+			city.pinned = true
+		}
+
+		all[city.Name] = city
+		defs[city] = def
+		if i < len(s.Cities) {
+			w.Cities[city.Name] = city
+		} else {
+			w.DestroyedCities = append(w.DestroyedCities, city)
+		}
+	}
+
+	// Roads are restored as they are, since the World might have more than one
+	// road in the same direction, e.g. after adding the opposite ones
+	for city, def := range defs {
+		for _, road := range def.Neighbors {
+			neighbor, ok := all[road.Name]
+			if !ok {
+				return nil, fmt.Errorf("invalid snapshot: road from %s to unknown city %q", city.Name, road.Name)
+			}
+			if _, ok := city.neighborMap[neighbor]; ok {
+				return nil, fmt.Errorf("invalid snapshot: duplicated road from %s to %s", city.Name, road.Name)
+			}
+
+			if road.Length < 0 {
+				return nil, fmt.Errorf("invalid length for the road from %s to %s: %g", city.Name, road.Name, road.Length)
+			}
+
+			var dir direction
+			if road.Direction != "" {
+				var err error
+				if dir, err = stringToDirection(road.Direction); err != nil {
+					return nil, fmt.Errorf("error converting to direction: %w", err)
+				}
+			}
+
+			city.Neighbors = append(city.Neighbors, neighbor)
+			city.neighborMap[neighbor] = dir
+			city.setLength(neighbor, road.Length)
+		}
+	}
+
+	if s.Turn < 0 {
+		return nil, fmt.Errorf("invalid snapshot: negative turn %d", s.Turn)
+	}
+	w.turn = s.Turn
+	for _, block := range s.Blocked {
+		b, err := blockedFromSnapshot(block, all, w.turn)
+		if err != nil {
+			return nil, err
+		}
+		w.blocked = append(w.blocked, b)
+	}
+
+	return &w, nil
+}
+
+func blockedFromSnapshot(block jsonBlockedRoad, all map[string]*City, turn int) (*BlockedRoad, error) {
+	from, to := all[block.From], all[block.To]
+	if from == nil || to == nil {
+		return nil, fmt.Errorf("invalid snapshot: blocked road from %q to %q between unknown cities", block.From, block.To)
+	}
+	if block.Until <= turn {
+		return nil, fmt.Errorf("invalid snapshot: the road from %s to %s should have opened in turn %d", from.Name, to.Name, block.Until)
+	}
+
+	b := &BlockedRoad{Road: Road{From: from, To: to}, Until: block.Until}
+	for _, road := range block.Roads {
+		e := roadEdit{from: all[road.From], to: all[road.To], length: road.Length}
+		if e.from == nil || e.to == nil {
+			return nil, fmt.Errorf("invalid snapshot: blocked road from %q to %q between unknown cities", road.From, road.To)
+		}
+		if _, ok := e.from.neighborMap[e.to]; ok {
+			return nil, fmt.Errorf("invalid snapshot: the road from %s to %s is both blocked and open", e.from.Name, e.to.Name)
+		}
+		if road.Length < 0 {
+			return nil, fmt.Errorf("invalid length for the road from %s to %s: %g", e.from.Name, e.to.Name, road.Length)
+		}
+		if road.Direction != "" {
+			var err error
+			if e.dir, err = stringToDirection(road.Direction); err != nil {
+				return nil, fmt.Errorf("error converting to direction: %w", err)
+			}
+		}
+		b.edits = append(b.edits, e)
+	}
+
+	return b, nil
+}
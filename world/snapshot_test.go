@@ -0,0 +1,113 @@
+package world
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshot(t *testing.T) {
+	for _, directed := range []bool{false, true} {
+		w, err := NewFromBytes([]byte(`[
+			{"name": "A", "x": 100, "y": 200, "neighbors": ["D", {"name": "B", "direction": "east"}, "C"]},
+			{"name": "C", "population": 300, "hit_points": 2, "neighbors": ["B"]}
+		]`), directed)
+		if !assert.NoError(t, err) {
+			return
+		}
+		w.DeleteCityAndRoads(w.Cities["C"])
+
+		restored, err := FromSnapshot(w.Snapshot())
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		// Roads keep their order, and destroyed cities keep the roads they had
+		assert.Equal(t, w.Snapshot(), restored.Snapshot())
+		assert.Equal(t, w.String(), restored.String())
+		assert.Equal(t, []string{"D", "B"}, names(restored.Cities["A"].Neighbors))
+		assert.Equal(t, Position{X: 100, Y: 200}, restored.Cities["A"].Position)
+		assert.True(t, restored.Cities["A"].pinned)
+		assert.Equal(t, "C", restored.DestroyedCities[0].Name)
+		assert.Equal(t, Attributes{Population: 300, HitPoints: 2}, restored.DestroyedCities[0].Attributes)
+		assert.Equal(t, []string{"B"}, names(restored.DestroyedCities[0].Neighbors)[len(restored.DestroyedCities[0].Neighbors)-1:])
+		assert.Equal(t, directed, restored.directed)
+	}
+}
+
+func TestFromSnapshot(t *testing.T) {
+	tests := []struct {
+		name string
+		s    Snapshot
+		err  string
+	}{
+		{
+			"duplicated city",
+			Snapshot{Cities: []jsonCityDefinition{{Name: "A"}}, Destroyed: []jsonCityDefinition{{Name: "A"}}},
+			`invalid snapshot: duplicated city "A"`,
+		},
+		{
+			"road to unknown city",
+			Snapshot{Cities: []jsonCityDefinition{{Name: "A", Neighbors: []jsonRoad{{Name: "B"}}}}},
+			`invalid snapshot: road from A to unknown city "B"`,
+		},
+		{
+			"empty name",
+			Snapshot{Cities: []jsonCityDefinition{{Name: ""}}},
+			"invalid snapshot: empty name for city 0",
+		},
+		{
+			"duplicated road",
+			Snapshot{Cities: []jsonCityDefinition{{Name: "A", Neighbors: []jsonRoad{{Name: "A"}, {Name: "A"}}}}},
+			"invalid snapshot: duplicated road from A to A",
+		},
+		{
+			"invalid direction",
+			Snapshot{Cities: []jsonCityDefinition{{Name: "A", Neighbors: []jsonRoad{{Name: "A", Direction: "up"}}}}},
+			`error converting to direction: cannot convert string "up" to direction type`,
+		},
+		{
+			"invalid attributes",
+			Snapshot{Cities: []jsonCityDefinition{{Name: "A", Attributes: Attributes{Defense: -1}}}},
+			"invalid defense for A: must be between 0 and 1, got -1",
+		},
+		{
+			"negative turn",
+			Snapshot{Turn: -1},
+			"invalid snapshot: negative turn -1",
+		},
+		{
+			"blocked road between unknown cities",
+			Snapshot{Cities: []jsonCityDefinition{{Name: "A"}}, Blocked: []jsonBlockedRoad{{From: "A", To: "B", Until: 1}}},
+			`invalid snapshot: blocked road from "A" to "B" between unknown cities`,
+		},
+		{
+			"blocked road that should have opened",
+			Snapshot{Cities: []jsonCityDefinition{{Name: "A"}, {Name: "B"}}, Turn: 2, Blocked: []jsonBlockedRoad{{From: "A", To: "B", Until: 2}}},
+			"invalid snapshot: the road from A to B should have opened in turn 2",
+		},
+		{
+			"blocked road that is open",
+			Snapshot{
+				Cities:  []jsonCityDefinition{{Name: "A", Neighbors: []jsonRoad{{Name: "B"}}}, {Name: "B"}},
+				Blocked: []jsonBlockedRoad{{From: "A", To: "B", Until: 1, Roads: []jsonRemovedRoad{{From: "A", To: "B"}}}},
+			},
+			"invalid snapshot: the road from A to B is both blocked and open",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			_, err := FromSnapshot(test.s)
+			assert.EqualError(tt, err, test.err)
+		})
+	}
+}
+
+func names(cities []*City) []string {
+	result := make([]string, len(cities))
+	for i, c := range cities {
+		result[i] = c.Name
+	}
+	return result
+}
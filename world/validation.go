@@ -0,0 +1,294 @@
+package world
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Severity tells how serious an issue found in a map is.
+type Severity string
+
+const (
+	// SeverityError is used for issues that make the map invalid or ambiguous.
+	SeverityError Severity = "error"
+	// SeverityWarning is used for issues that the loaders tolerate but are probably a mistake.
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is a structural problem found in a map.
+type Issue struct {
+	Severity Severity
+	// Definition is the number of the city definition where the issue was found, starting at 1.
+	// It is the line number for text maps and the array element for JSON maps.
+	// It's 0 for issues that involve several definitions, like the number of roads of a city.
+	Definition int
+	City       string
+	// Road is the name of the neighbor city, empty if the issue is not about a single road.
+	Road    string
+	Message string
+}
+
+func (i Issue) String() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "%s:", i.Severity)
+	if i.Definition > 0 {
+		fmt.Fprintf(&builder, " definition %d:", i.Definition)
+	}
+	if i.City != "" {
+		fmt.Fprintf(&builder, " %s:", i.City)
+	}
+	if i.Road != "" {
+		fmt.Fprintf(&builder, " road to %s:", i.Road)
+	}
+	fmt.Fprintf(&builder, " %s", i.Message)
+
+	return builder.String()
+}
+
+// Validate reads a map and returns every structural problem found in it, instead of
+// failing on the first one like the loaders do. An error is only returned if the map
+// can't be read at all.
+func Validate(r io.Reader, format Format, isDirected bool) ([]Issue, error) {
+	var (
+		defs   []numberedCityDefinition
+		issues []Issue
+	)
+
+	switch format {
+	case FormatText:
+		scanner := bufio.NewScanner(r)
+		for lineNumber := 1; scanner.Scan(); lineNumber++ {
+			line := scanner.Text()
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+
+			cityDef, err := parseLine(line)
+			if err != nil {
+				issues = append(issues, Issue{
+					Severity:   SeverityError,
+					Definition: lineNumber,
+					City:       strings.Fields(line)[0],
+					Message:    err.Error(),
+				})
+				continue
+			}
+			defs = append(defs, numberedCityDefinition{cityDef, lineNumber})
+		}
+
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("error reading map: %w", err)
+		}
+
+	case FormatJSON:
+		var jsonCityDefs []jsonCityDefinition
+		if err := json.NewDecoder(r).Decode(&jsonCityDefs); err != nil {
+			return nil, fmt.Errorf("error unmarshaling map: %w", err)
+		}
+
+		for i, jsonCityDef := range jsonCityDefs {
+			// Report negative lengths on their own road, and drop them so the rest of the city is checked
+			for j, road := range jsonCityDef.Neighbors {
+				if road.Length < 0 {
+					issues = append(issues, Issue{
+						Severity:   SeverityError,
+						Definition: i + 1,
+						City:       jsonCityDef.Name,
+						Road:       road.Name,
+						Message:    fmt.Sprintf("invalid length: %g", road.Length),
+					})
+					jsonCityDef.Neighbors[j].Length = 0
+				}
+			}
+
+			cityDef, err := parseJSONCity(jsonCityDef)
+			if err != nil {
+				issues = append(issues, Issue{
+					Severity:   SeverityError,
+					Definition: i + 1,
+					City:       jsonCityDef.Name,
+					Message:    err.Error(),
+				})
+				continue
+			}
+			defs = append(defs, numberedCityDefinition{cityDef, i + 1})
+		}
+
+	default:
+		return nil, fmt.Errorf("invalid format: %q", format)
+	}
+
+	issues = append(issues, validateDefinitions(defs, isDirected)...)
+
+	// Sort by definition, leaving issues that involve several definitions at the end
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Definition == 0 || issues[j].Definition == 0 {
+			return issues[j].Definition == 0 && issues[i].Definition != 0
+		}
+		return issues[i].Definition < issues[j].Definition
+	})
+
+	return issues, nil
+}
+
+// numberedCityDefinition is a city definition along with its number in the map.
+type numberedCityDefinition struct {
+	*cityDefinition
+	number int
+}
+
+// validateDefinitions checks the relations between city definitions.
+func validateDefinitions(defs []numberedCityDefinition, isDirected bool) []Issue {
+	var issues []Issue
+
+	// Definition number of each city, used to find duplicates and dangling roads
+	defined := make(map[string]int, len(defs))
+	for _, def := range defs {
+		if first, ok := defined[def.name]; ok {
+			issues = append(issues, Issue{
+				Severity:   SeverityWarning,
+				Definition: def.number,
+				City:       def.name,
+				Message:    fmt.Sprintf("city is defined more than once, first definition is %d", first),
+			})
+			continue
+		}
+		defined[def.name] = def.number
+	}
+
+	// roads maps each city with its neighbors and the direction of each road,
+	// including the roads added in the opposite direction for non-directed graphs.
+	roads := make(map[string]map[string]direction)
+	addRoad := func(from, to string, dir direction) (direction, bool) {
+		if roads[from] == nil {
+			roads[from] = make(map[string]direction, maxRoads)
+		}
+
+		existing, ok := roads[from][to]
+		if ok && existing != "" && dir != "" && existing != dir {
+			return existing, false
+		}
+		if !ok || existing == "" {
+			roads[from][to] = dir
+		}
+
+		return "", true
+	}
+
+	// lengths has the length declared for each road of non-directed graphs, by its cities in
+	// alphabetical order, to check that both ends of the road agree
+	lengths := make(map[[2]string]float64)
+
+	for _, def := range defs {
+		for _, neighbor := range def.neighbors {
+			if neighbor == def.name {
+				issues = append(issues, Issue{
+					Severity:   SeverityError,
+					Definition: def.number,
+					City:       def.name,
+					Road:       neighbor,
+					Message:    "road leads to the same city",
+				})
+				continue
+			}
+
+			if _, ok := defined[neighbor]; !ok {
+				issues = append(issues, Issue{
+					Severity:   SeverityWarning,
+					Definition: def.number,
+					City:       def.name,
+					Road:       neighbor,
+					Message:    "neighbor city is never defined",
+				})
+			}
+
+			dir := def.neighborMap[neighbor]
+			if existing, ok := addRoad(def.name, neighbor, dir); !ok {
+				issues = append(issues, Issue{
+					Severity:   SeverityError,
+					Definition: def.number,
+					City:       def.name,
+					Road:       neighbor,
+					Message:    fmt.Sprintf("contradictory directions: %s is both %s and %s of %s", neighbor, existing, dir, def.name),
+				})
+				continue
+			}
+
+			if isDirected {
+				continue
+			}
+
+			if existing, ok := addRoad(neighbor, def.name, oppositeDirectionMap[dir]); !ok {
+				issues = append(issues, Issue{
+					Severity:   SeverityError,
+					Definition: def.number,
+					City:       def.name,
+					Road:       neighbor,
+					Message:    fmt.Sprintf("contradictory directions: %s is both %s and %s of %s", def.name, existing, oppositeDirectionMap[dir], neighbor),
+				})
+			}
+
+			length := def.lengths[neighbor]
+			if length == 0 {
+				continue
+			}
+			key := [2]string{def.name, neighbor}
+			if key[1] < key[0] {
+				key[0], key[1] = key[1], key[0]
+			}
+			if existing, ok := lengths[key]; ok && existing != length {
+				issues = append(issues, Issue{
+					Severity:   SeverityError,
+					Definition: def.number,
+					City:       def.name,
+					Road:       neighbor,
+					Message:    fmt.Sprintf("inconsistent lengths: the road between %s and %s is both %g and %g long", def.name, neighbor, existing, length),
+				})
+				continue
+			}
+			lengths[key] = length
+		}
+	}
+
+	// Check every city's roads as a whole, sorted by name to get a stable output
+	cities := make([]string, 0, len(roads))
+	for city := range roads {
+		cities = append(cities, city)
+	}
+	sort.Strings(cities)
+
+	for _, city := range cities {
+		if len(roads[city]) > maxRoads {
+			issues = append(issues, Issue{
+				Severity: SeverityError,
+				City:     city,
+				Message:  fmt.Sprintf("too many roads: %d, maximum is %d", len(roads[city]), maxRoads),
+			})
+		}
+
+		// Each direction can only lead to one city
+		byDirection := make(map[direction][]string, maxRoads)
+		for neighbor, dir := range roads[city] {
+			if dir != "" {
+				byDirection[dir] = append(byDirection[dir], neighbor)
+			}
+		}
+
+		for _, dir := range []direction{north, south, east, west} {
+			if len(byDirection[dir]) > 1 {
+				sort.Strings(byDirection[dir])
+				issues = append(issues, Issue{
+					Severity: SeverityError,
+					City:     city,
+					Message:  fmt.Sprintf("contradictory directions: %s leads to %s", dir, strings.Join(byDirection[dir], ", ")),
+				})
+			}
+		}
+	}
+
+	return issues
+}
@@ -0,0 +1,172 @@
+package world
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		format   Format
+		directed bool
+		expected []Issue
+		err      string
+	}{
+		{
+			"valid text map",
+			"Gerli north=Lanús\nLanús south=Gerli",
+			FormatText,
+			false,
+			nil,
+			"",
+		},
+		{
+			"syntax errors don't stop the validation",
+			"Gerli southeast=Lanús\nLanús north=Lanús",
+			FormatText,
+			false,
+			[]Issue{
+				{SeverityError, 1, "Gerli", "", `error converting to direction: cannot convert string "southeast" to direction type`},
+				{SeverityError, 2, "Lanús", "Lanús", "road leads to the same city"},
+			},
+			"",
+		},
+		{
+			"duplicated city and dangling neighbor",
+			"Gerli north=Lanús\nGerli south=Bernal\nLanús south=Gerli",
+			FormatText,
+			true,
+			[]Issue{
+				{SeverityWarning, 2, "Gerli", "", "city is defined more than once, first definition is 1"},
+				{SeverityWarning, 2, "Gerli", "Bernal", "neighbor city is never defined"},
+			},
+			"",
+		},
+		{
+			"neighbor repeated in a definition",
+			"F north=G south=G\nG",
+			FormatText,
+			false,
+			[]Issue{
+				{SeverityError, 1, "F", "", `duplicated road to "G"`},
+			},
+			"",
+		},
+		{
+			"neighbor repeated in a JSON definition",
+			`[{"name": "F", "neighbors": [{"name": "G", "direction": "north"}, {"name": "G", "direction": "south"}]}]`,
+			FormatJSON,
+			false,
+			[]Issue{
+				{SeverityError, 1, "F", "", `duplicated road to "G"`},
+			},
+			"",
+		},
+		{
+			"contradictory directions, non-directed",
+			"Gerli north=Lanús\nLanús east=Gerli",
+			FormatText,
+			false,
+			[]Issue{
+				{SeverityError, 2, "Lanús", "Gerli", "contradictory directions: Gerli is both south and east of Lanús"},
+			},
+			"",
+		},
+		{
+			"contradictory directions, directed",
+			"Gerli north=Lanús\nLanús east=Gerli",
+			FormatText,
+			true,
+			nil,
+			"",
+		},
+		{
+			"same direction in different definitions",
+			"Gerli north=Lanús\nGerli north=Bernal\nLanús\nBernal",
+			FormatText,
+			true,
+			[]Issue{
+				{SeverityWarning, 2, "Gerli", "", "city is defined more than once, first definition is 1"},
+				{SeverityError, 0, "Gerli", "", "contradictory directions: north leads to Bernal, Lanús"},
+			},
+			"",
+		},
+		{
+			"too many roads",
+			`[{"name": "A", "neighbors": ["B", "C", "D", "E"]}, {"name": "F", "neighbors": ["A"]}]`,
+			FormatJSON,
+			false,
+			[]Issue{
+				{SeverityWarning, 1, "A", "B", "neighbor city is never defined"},
+				{SeverityWarning, 1, "A", "C", "neighbor city is never defined"},
+				{SeverityWarning, 1, "A", "D", "neighbor city is never defined"},
+				{SeverityWarning, 1, "A", "E", "neighbor city is never defined"},
+				{SeverityError, 0, "A", "", "too many roads: 5, maximum is 4"},
+			},
+			"",
+		},
+		{
+			"invalid JSON element",
+			`[{"name": "A", "neighbors": [{"name": "B", "direction": "up"}]}, {"name": "B"}]`,
+			FormatJSON,
+			false,
+			[]Issue{
+				{SeverityError, 1, "A", "", `error converting to direction: cannot convert string "up" to direction type`},
+			},
+			"",
+		},
+		{
+			"inconsistent lengths, non-directed",
+			`[{"name": "A", "neighbors": [{"name": "B", "length": 120}, {"name": "C", "length": 80}]}, {"name": "B", "neighbors": [{"name": "A", "length": 90}]}, {"name": "C", "neighbors": [{"name": "A", "length": 80}]}]`,
+			FormatJSON,
+			false,
+			[]Issue{
+				{SeverityError, 2, "B", "A", "inconsistent lengths: the road between B and A is both 120 and 90 long"},
+			},
+			"",
+		},
+		{
+			"inconsistent lengths, directed",
+			`[{"name": "A", "neighbors": [{"name": "B", "length": 120}]}, {"name": "B", "neighbors": [{"name": "A", "length": 90}]}]`,
+			FormatJSON,
+			true,
+			nil,
+			"",
+		},
+		{
+			"negative length",
+			`[{"name": "A", "neighbors": [{"name": "B", "length": -3}, "C"]}, {"name": "B"}, {"name": "C", "neighbors": ["A"]}]`,
+			FormatJSON,
+			false,
+			[]Issue{
+				{SeverityError, 1, "A", "B", "invalid length: -3"},
+			},
+			"",
+		},
+		{
+			"unreadable JSON",
+			`{"name": "A"}`,
+			FormatJSON,
+			false,
+			nil,
+			"error unmarshaling map: json: cannot unmarshal object into Go value of type []world.jsonCityDefinition",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			issues, err := Validate(strings.NewReader(test.input), test.format, test.directed)
+			if test.err != "" {
+				assert.EqualError(tt, err, test.err)
+				return
+			}
+
+			assert.NoError(tt, err)
+			assert.Equal(tt, test.expected, issues)
+		})
+	}
+}
@@ -3,28 +3,45 @@ package world
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"math/rand"
+	"sort"
 	"strings"
-
-	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
+// defaultSeed is the seed of the random source used when none is set, so that
+// worlds behave the same way across runs unless told otherwise.
+const defaultSeed = 1
+
 // World is a graph with interconnected cities.
 type World struct {
 	Cities          map[string]*City
 	DestroyedCities []*City
 	directed        bool
+	// rng is the source for every random decision taken by the World
+	rng *rand.Rand
+	// turn is the amount of times Tick was called, and blocked the roads currently blocked
+	turn    int
+	blocked []*BlockedRoad
 }
 
-type position struct {
-	X int32
-	Y int32
+// Position is the location of a city in a two-dimensional plane.
+type Position struct {
+	X float32
+	Y float32
 }
 
+// cityDefinition is the parsed definition of a city and its roads,
+// regardless of the format of the map it comes from.
 type cityDefinition struct {
-	Name        string   `json:"name"`
-	Neighbors   []string `json:"neighbors"`
+	name        string
+	neighbors   []string
 	neighborMap map[string]direction
+	// lengths has the lengths of the roads that declare one
+	lengths map[string]float64
+	// position is only set when the map pins the city to specific coordinates
+	position   *Position
+	attributes Attributes
 }
 
 // City is an edge on the graph.
@@ -32,44 +49,91 @@ type City struct {
 	Name        string
 	Neighbors   []*City
 	neighborMap map[*City]direction
-	Position    rl.Vector2
+	// lengths has the lengths declared in the map for the roads to some of the neighbors
+	lengths  map[*City]float64
+	Position Position
+	// pinned cities keep the position defined in the map
+	pinned     bool
+	Attributes Attributes
 }
 
-// NewFromBytes returns a new World based on raw bytes.
-func NewFromBytes(b []byte, isDirected bool, width int32, height int32) (*World, error) {
+// Length returns the length of the road from the city to a neighbor: the one declared in the map,
+// or the distance between both cities if they're pinned, or 0 if it's unknown.
+func (c *City) Length(to *City) float64 {
+	if length, ok := c.lengths[to]; ok {
+		return length
+	}
+	if c.pinned && to.pinned {
+		return math.Hypot(float64(c.Position.X-to.Position.X), float64(c.Position.Y-to.Position.Y))
+	}
+
+	return 0
+}
+
+// setLength sets the length of the road from the city to a neighbor, if it's declared.
+func (c *City) setLength(to *City, length float64) {
+	if length <= 0 {
+		return
+	}
+	if c.lengths == nil {
+		c.lengths = make(map[*City]float64)
+	}
+	c.lengths[to] = length
+}
+
+// NewFromBytes returns a new World based on a JSON map.
+func NewFromBytes(b []byte, isDirected bool) (*World, error) {
 	world := World{
 		Cities:   make(map[string]*City),
 		directed: isDirected,
 	}
 
-	// Parse city and roads from each file line
-	var cityDefs []cityDefinition
-	if err := json.Unmarshal(b, &cityDefs); err != nil {
+	// Parse city and roads from each array element
+	var jsonCityDefs []jsonCityDefinition
+	if err := json.Unmarshal(b, &jsonCityDefs); err != nil {
 		return nil, fmt.Errorf("error unmarshaling bytes: %w", err)
 	}
 
-	for _, cityDef := range cityDefs {
-		world.addCityAndRoads(&cityDef, width, height)
+	for i, jsonCityDef := range jsonCityDefs {
+		cityDef, err := parseJSONCity(jsonCityDef)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing city %d: %w", i, err)
+		}
+
+		if err := world.addCityAndRoads(cityDef); err != nil {
+			return nil, fmt.Errorf("error adding city %d: %w", i, err)
+		}
 	}
 
 	return &world, nil
 }
 
-func (w *World) addCityAndRoads(cityDef *cityDefinition, width int32, height int32) {
+func (w *World) addCityAndRoads(cityDef *cityDefinition) error {
+	if cityDef == nil {
+		return fmt.Errorf("invalid city definition: <nil>")
+	}
+
 	// Create or retrieve city, name must be unique
-	cityFrom, ok := w.Cities[cityDef.Name]
+	cityFrom, ok := w.Cities[cityDef.name]
 	if !ok {
 		cityFrom = &City{
-			Position:    rl.NewVector2(rand.Float32()*float32(width-100)+50, rand.Float32()*float32(height-100)+50),
-			Name:        cityDef.Name,
+			Name:        cityDef.name,
 			neighborMap: make(map[*City]direction, maxRoads),
 		}
 		// Add newly created city to World
 		w.Cities[cityFrom.Name] = cityFrom
 	}
 
+	if cityDef.position != nil {
+		cityFrom.Position = *cityDef.position
+		cityFrom.pinned = true
+	}
+	if cityDef.attributes != (Attributes{}) {
+		cityFrom.Attributes = cityDef.attributes
+	}
+
 	// Add roads to neighbor cities
-	for _, neighborName := range cityDef.Neighbors {
+	for _, neighborName := range cityDef.neighbors {
 		// Define directions both ways
 		cityToNeighborDir := cityDef.neighborMap[neighborName]
 		neighborToCityDir := oppositeDirectionMap[cityToNeighborDir]
@@ -79,32 +143,172 @@ func (w *World) addCityAndRoads(cityDef *cityDefinition, width int32, height int
 			// If the neighbor city hasn't been created yet,
 			// create it and add it to the World before proceeding.
 			cityTo = &City{
-				Position:    rl.NewVector2(rand.Float32()*float32(width-100)+50, rand.Float32()*float32(height-100)+50),
 				Name:        neighborName,
 				neighborMap: make(map[*City]direction, maxRoads),
 			}
 			w.Cities[neighborName] = cityTo
 		}
 
-		// Append to city neighbors only if it's not already there
-		if _, ok := cityFrom.neighborMap[cityTo]; !ok {
-			cityFrom.Neighbors = append(cityFrom.Neighbors, cityTo)
+		// If the road already exists, it might have been added from the neighbor's
+		// definition. Check that both ends agree on the direction and the length.
+		if existingDir, ok := cityFrom.neighborMap[cityTo]; ok {
+			if w.directed {
+				continue
+			}
+
+			if length := cityDef.lengths[neighborName]; length > 0 {
+				if existing, ok := cityFrom.lengths[cityTo]; ok && existing != length {
+					return fmt.Errorf("inconsistent lengths: the road between %s and %s is both %g and %g long", cityFrom.Name, cityTo.Name, existing, length)
+				}
+				cityFrom.setLength(cityTo, length)
+				cityTo.setLength(cityFrom, length)
+			}
+
+			if cityToNeighborDir == "" {
+				continue
+			}
+
+			if existingDir != "" && existingDir != cityToNeighborDir {
+				return fmt.Errorf("inconsistent directions: %s is both %s and %s of %s", cityTo.Name, existingDir, cityToNeighborDir, cityFrom.Name)
+			}
+			if err := cityFrom.checkDirection(cityTo, cityToNeighborDir); err != nil {
+				return err
+			}
+			if err := cityTo.checkDirection(cityFrom, neighborToCityDir); err != nil {
+				return err
+			}
+
 			cityFrom.neighborMap[cityTo] = cityToNeighborDir
+			cityTo.neighborMap[cityFrom] = neighborToCityDir
+			continue
+		}
 
-			// If the graph is non-directed, make the connection bi-directional
-			if !w.directed {
-				cityTo.Neighbors = append(cityTo.Neighbors, cityFrom)
-				cityTo.neighborMap[cityFrom] = neighborToCityDir
+		// Check both ends before adding the road, so a failed definition doesn't leave half of it
+		if err := cityFrom.checkRoad(cityTo, cityToNeighborDir); err != nil {
+			return err
+		}
+		if !w.directed {
+			if err := cityTo.checkRoad(cityFrom, neighborToCityDir); err != nil {
+				return err
 			}
 		}
+
+		cityFrom.Neighbors = append(cityFrom.Neighbors, cityTo)
+		cityFrom.neighborMap[cityTo] = cityToNeighborDir
+		cityFrom.setLength(cityTo, cityDef.lengths[neighborName])
+
+		// If the graph is non-directed, make the connection bi-directional
+		if !w.directed {
+			cityTo.Neighbors = append(cityTo.Neighbors, cityFrom)
+			cityTo.neighborMap[cityFrom] = neighborToCityDir
+			cityTo.setLength(cityFrom, cityDef.lengths[neighborName])
+		}
+	}
+
+	return nil
+}
+
+// checkRoad checks that the city can have a new road to another city in a direction, which can be empty.
+func (c *City) checkRoad(to *City, dir direction) error {
+	if len(c.neighborMap) >= maxRoads {
+		return fmt.Errorf("too many roads: %s can't have more than %d", c.Name, maxRoads)
+	}
+	return c.checkDirection(to, dir)
+}
+
+// checkDirection checks that no other neighbor of the city is in the given direction, which can be empty.
+func (c *City) checkDirection(to *City, dir direction) error {
+	if dir == "" {
+		return nil
+	}
+	for n, d := range c.neighborMap {
+		if d == dir && n != to {
+			return fmt.Errorf("inconsistent directions: both %s and %s are %s of %s", n.Name, to.Name, dir, c.Name)
+		}
+	}
+	return nil
+}
+
+// SetRand sets the random source used by the World.
+// Worlds use a source with a fixed seed if none is set.
+func (w *World) SetRand(rng *rand.Rand) {
+	w.rng = rng
+}
+
+func (w *World) random() *rand.Rand {
+	if w.rng == nil {
+		w.rng = rand.New(rand.NewSource(defaultSeed))
+	}
+
+	return w.rng
+}
+
+// Directed reports whether roads only go one way.
+func (w *World) Directed() bool {
+	return w.directed
+}
+
+// SortedCities returns the cities in the World sorted by name.
+// Use it instead of ranging over Cities when the order matters, e.g. to get reproducible results.
+func (w *World) SortedCities() []*City {
+	cities := make([]*City, 0, len(w.Cities))
+	for _, city := range w.Cities {
+		cities = append(cities, city)
+	}
+	sort.Slice(cities, func(i, j int) bool { return cities[i].Name < cities[j].Name })
+
+	return cities
+}
+
+// Scatter places every city in a random position inside an area of the given dimensions,
+// keeping a margin of 50 units from the borders. Cities pinned by the map are not moved.
+func (w *World) Scatter(width int32, height int32) {
+	rng := w.random()
+	for _, city := range w.SortedCities() {
+		if city.pinned {
+			continue
+		}
+
+		city.Position = Position{
+			X: rng.Float32()*float32(width-100) + 50,
+			Y: rng.Float32()*float32(height-100) + 50,
+		}
 	}
 }
 
-// DeleteCityAndRoads removes a city and all its edges from the World.
-func (w *World) DeleteCityAndRoads(city *City) {
+// Deletion records what DeleteCityAndRoads changed, so that RestoreCity can undo it.
+type Deletion struct {
+	city *City
+	// roads has the neighbors of each city that lost its road to the deleted one,
+	// in their original order, along with the direction of the lost road
+	roads map[*City]lostRoad
+	// blocked are the blocked roads before the deletion, if some of them involved the city
+	blocked []*BlockedRoad
+}
+
+type lostRoad struct {
+	neighbors []*City
+	dir       direction
+}
+
+// DeleteCityAndRoads removes a city and all its edges from the World, including its blocked roads,
+// which won't open again. The returned Deletion can be used to put them back with RestoreCity.
+func (w *World) DeleteCityAndRoads(city *City) Deletion {
 	// Delete City from the World's City map
 	w.DestroyedCities = append(w.DestroyedCities, city)
 	delete(w.Cities, city.Name)
+	deletion := Deletion{city: city, roads: make(map[*City]lostRoad)}
+
+	remaining := make([]*BlockedRoad, 0, len(w.blocked))
+	for _, b := range w.blocked {
+		if b.From != city && b.To != city {
+			remaining = append(remaining, b)
+		}
+	}
+	if len(remaining) != len(w.blocked) {
+		deletion.blocked = w.blocked
+		w.blocked = remaining
+	}
 
 	// If it's not a directed graph, delete all roads to the city
 	// from its neighbors' adjacency lists
@@ -117,10 +321,11 @@ func (w *World) DeleteCityAndRoads(city *City) {
 				}
 			}
 
+			deletion.roads[neighbor] = lostRoad{neighbors: neighbor.Neighbors, dir: neighbor.neighborMap[city]}
 			neighbor.Neighbors = newNeighbors
 			delete(neighbor.neighborMap, city)
 		}
-		return
+		return deletion
 	}
 
 	// If it's directed, we have to check node by node
@@ -134,20 +339,50 @@ func (w *World) DeleteCityAndRoads(city *City) {
 		}
 
 		if len(newNeighbors) != len(c.Neighbors) {
+			deletion.roads[c] = lostRoad{neighbors: c.Neighbors, dir: c.neighborMap[city]}
 			c.Neighbors = newNeighbors
 			delete(c.neighborMap, city)
 		}
 	}
+
+	return deletion
+}
+
+// RestoreCity undoes a deletion, putting the city back in the World along with every road to it.
+// Deletions must be undone in the opposite order they were made, starting from the last one.
+func (w *World) RestoreCity(d Deletion) error {
+	last := len(w.DestroyedCities) - 1
+	if last < 0 || w.DestroyedCities[last] != d.city {
+		return fmt.Errorf("invalid deletion: %s is not the last destroyed city", d.city.Name)
+	}
+
+	w.DestroyedCities = w.DestroyedCities[:last]
+	w.Cities[d.city.Name] = d.city
+	for c, road := range d.roads {
+		c.Neighbors = road.neighbors
+		c.neighborMap[d.city] = road.dir
+	}
+	if d.blocked != nil {
+		w.blocked = d.blocked
+	}
+
+	return nil
 }
 
 // String returns the string representation of the World
-// using the same format as the input file.
+// using the text map format, sorted by city name.
+// Roads without a direction can't be written in the text format, so they're left out.
 func (w *World) String() string {
 	var builder strings.Builder
-	for _, city := range w.Cities {
+	for _, city := range w.SortedCities() {
 		fmt.Fprintf(&builder, "%s", city.Name)
-		for n, dir := range city.neighborMap {
-			fmt.Fprintf(&builder, " %s=%s", dir, n.Name)
+
+		// Sort roads by neighbor name so the output is stable
+		for _, n := range sortedNeighbors(city) {
+			if city.neighborMap[n] == "" {
+				continue
+			}
+			fmt.Fprintf(&builder, " %s=%s", city.neighborMap[n], n.Name)
 		}
 		fmt.Fprintln(&builder)
 	}
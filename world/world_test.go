@@ -1,6 +1,7 @@
 package world
 
 import (
+	"bytes"
 	"fmt"
 	"strings"
 	"testing"
@@ -341,6 +342,23 @@ func TestString(t *testing.T) {
 			}
 		})
 	}
+
+	t.Run("JSON maps can be loaded back, without the roads lacking a direction", func(tt *testing.T) {
+		w, err := NewFromBytes([]byte(`[
+			{"name": "Gerli", "neighbors": [{"name": "Lanús", "direction": "north"}, "Bernal"]},
+			{"name": "Bernal", "neighbors": ["Quilmes"]}
+		]`), false)
+		if !assert.NoError(tt, err) {
+			return
+		}
+
+		loaded, err := NewFromReader(strings.NewReader(w.String()), false)
+		if !assert.NoError(tt, err) {
+			return
+		}
+		assert.Equal(tt, "Bernal\nGerli north=Lanús\nLanús south=Gerli\nQuilmes\n", loaded.String())
+		assert.Equal(tt, w.String(), loaded.String())
+	})
 }
 
 func TestWorld(t *testing.T) {
@@ -550,3 +568,112 @@ func TestWorld(t *testing.T) {
 		})
 	}
 }
+
+func TestRestoreCity(t *testing.T) {
+	for _, directed := range []bool{false, true} {
+		t.Run(fmt.Sprintf("directed %t", directed), func(tt *testing.T) {
+			w, err := NewFromReader(strings.NewReader("A north=B east=C\nB east=D\nC north=D south=E\nD east=F\nE west=F"), directed)
+			if !assert.NoError(tt, err) {
+				return
+			}
+			before := w.Snapshot()
+
+			first := w.DeleteCityAndRoads(w.Cities["D"])
+			second := w.DeleteCityAndRoads(w.Cities["C"])
+
+			// Deletions are undone starting from the last one
+			assert.EqualError(tt, w.RestoreCity(first), "invalid deletion: D is not the last destroyed city")
+			assert.NoError(tt, w.RestoreCity(second))
+			assert.NoError(tt, w.RestoreCity(first))
+
+			// Roads are back in their original order
+			assert.Equal(tt, before, w.Snapshot())
+		})
+	}
+}
+
+func TestLength(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		from, to string
+		expected float64
+		err      string
+	}{
+		{
+			"declared length, both ways",
+			`[{"name": "A", "neighbors": [{"name": "B", "length": 12.5}]}]`,
+			"B", "A",
+			12.5,
+			"",
+		},
+		{
+			"distance between pinned cities",
+			`[{"name": "A", "x": 0, "y": 0, "neighbors": ["B"]}, {"name": "B", "x": 30, "y": 40, "neighbors": []}]`,
+			"A", "B",
+			50,
+			"",
+		},
+		{
+			"declared length of pinned cities",
+			`[{"name": "A", "x": 0, "y": 0, "neighbors": [{"name": "B", "length": 7}]}, {"name": "B", "x": 30, "y": 40, "neighbors": []}]`,
+			"A", "B",
+			7,
+			"",
+		},
+		{
+			"unknown length",
+			`[{"name": "A", "x": 0, "y": 0, "neighbors": ["B"]}]`,
+			"A", "B",
+			0,
+			"",
+		},
+		{
+			"same length on both ends",
+			`[{"name": "A", "neighbors": [{"name": "B", "length": 3}]}, {"name": "B", "neighbors": [{"name": "A", "length": 3}]}]`,
+			"A", "B",
+			3,
+			"",
+		},
+		{
+			"inconsistent lengths",
+			`[{"name": "A", "neighbors": [{"name": "B", "length": 3}]}, {"name": "B", "neighbors": [{"name": "A", "length": 4}]}]`,
+			"", "",
+			0,
+			"error adding city 1: inconsistent lengths: the road between B and A is both 3 and 4 long",
+		},
+		{
+			"negative length",
+			`[{"name": "A", "neighbors": [{"name": "B", "length": -1}]}]`,
+			"", "",
+			0,
+			"error parsing city 0: invalid length for the road from A to B: -1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(tt *testing.T) {
+			w, err := NewFromBytes([]byte(test.input), false)
+			if test.err != "" {
+				assert.EqualError(tt, err, test.err)
+				return
+			}
+			if !assert.NoError(tt, err) {
+				return
+			}
+
+			assert.Equal(tt, test.expected, w.Cities[test.from].Length(w.Cities[test.to]))
+
+			// Lengths are kept when the World is written again
+			var buf bytes.Buffer
+			if !assert.NoError(tt, w.WriteJSON(&buf)) {
+				return
+			}
+			written, err := NewFromBytes(buf.Bytes(), false)
+			if !assert.NoError(tt, err) {
+				return
+			}
+			assert.Equal(tt, test.expected, written.Cities[test.from].Length(written.Cities[test.to]))
+		})
+	}
+}